@@ -4,8 +4,18 @@ import "github.com/zeromicro/go-zero/rest"
 
 type Config struct {
 	rest.RestConf
-	WebSockets []WebSocketConfig `json:",optional"`
-	Database   DatabaseConfig    `json:",optional"`
+	WebSockets  []WebSocketConfig `json:",optional"`
+	Database    DatabaseConfig    `json:",optional"`
+	Notifiers   NotifiersConfig   `json:",optional"`
+	RSIAlert    RSIAlertConfig    `json:",optional"`
+	Watchlist   []WatchConfig     `json:",optional"` // RSI 监控列表，为空时使用内置默认列表
+	Confluence  []ConfluenceRule  `json:",optional"` // RSI 多周期共振规则
+	Patterns    PatternsConfig    `json:",optional"` // K 线形态检测
+	Volume      VolumeConfig      `json:",optional"` // 成交量异动检测
+	Klines      KlinesConfig      `json:",optional"` // 历史K线拉取和缓存
+	PriceAlerts PriceAlertsConfig `json:",optional"` // 用户价格告警
+	Funding     FundingConfig     `json:",optional"` // 资金费率和持仓量监控
+	Liquidation LiquidationConfig `json:",optional"` // 清算监控
 }
 
 type WebSocketConfig struct {
@@ -28,4 +38,169 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `json:",optional"` // 最大打开连接数，默认10
 	MaxIdleConns    int    `json:",optional"` // 最大空闲连接数，默认5
 	ConnMaxLifetime int    `json:",optional"` // 连接最大生命周期(秒)，默认3600
-}
\ No newline at end of file
+}
+
+// NotifiersConfig 通知渠道配置
+type NotifiersConfig struct {
+	Expo     ExpoNotifierConfig     `json:",optional"` // Expo 推送（默认启用）
+	Telegram TelegramNotifierConfig `json:",optional"` // Telegram Bot
+	Discord  WebhookNotifierConfig  `json:",optional"` // Discord Webhook
+	Slack    WebhookNotifierConfig  `json:",optional"` // Slack Incoming Webhook
+	Email    EmailNotifierConfig    `json:",optional"` // 邮件
+}
+
+type ExpoNotifierConfig struct {
+	Disabled bool `json:",optional"` // 是否禁用 Expo 推送
+}
+
+type TelegramNotifierConfig struct {
+	Enabled       bool              `json:",optional"` // 是否启用
+	BotToken      string            `json:",optional"` // Bot Token
+	APIURL        string            `json:",optional"` // Bot API 地址，默认 https://api.telegram.org
+	DefaultChatID string            `json:",optional"` // 未单独配置来源时使用的 chat ID
+	ChatIDs       map[string]string `json:",optional"` // 按消息来源配置 chat ID: rsi/liquidation/news/pattern/webhook
+}
+
+type WebhookNotifierConfig struct {
+	Enabled    bool   `json:",optional"` // 是否启用
+	WebhookURL string `json:",optional"` // Webhook 地址
+}
+
+type EmailNotifierConfig struct {
+	Enabled  bool     `json:",optional"` // 是否启用
+	Host     string   `json:",optional"` // SMTP 服务器地址
+	Port     int      `json:",optional"` // SMTP 端口，默认587
+	Username string   `json:",optional"` // SMTP 用户名
+	Password string   `json:",optional"` // SMTP 密码
+	From     string   `json:",optional"` // 发件人地址
+	To       []string `json:",optional"` // 收件人地址列表
+}
+
+// RSIAlertConfig RSI 超买超卖告警配置
+type RSIAlertConfig struct {
+	Overbought float64        `json:",optional"` // 超买阈值，默认70
+	Oversold   float64        `json:",optional"` // 超卖阈值，默认30
+	Hysteresis float64        `json:",optional"` // 回差，RSI 需回到阈值内该距离后才会再次告警，默认2
+	Rules      []RSIAlertRule `json:",optional"` // 按交易对/周期覆盖阈值
+}
+
+type RSIAlertRule struct {
+	Symbol     string  `json:",optional"` // 交易对，如 btcusdt，为空匹配全部
+	Interval   string  `json:",optional"` // 周期，如 4h，为空匹配全部
+	Overbought float64 `json:",optional"` // 超买阈值
+	Oversold   float64 `json:",optional"` // 超卖阈值
+	Hysteresis float64 `json:",optional"` // 回差
+}
+
+// WatchConfig RSI 监控项，每个周期启动一个 watcher
+type WatchConfig struct {
+	Symbol     string   `json:",optional"` // 交易对，如 btcusdt
+	Intervals  []string `json:",optional"` // 周期列表，如 [2h, 4h, 1d]
+	Period     int      `json:",optional"` // RSI 周期，默认14
+	Overbought float64  `json:",optional"` // 超买阈值，为空使用 RSIAlert 配置
+	Oversold   float64  `json:",optional"` // 超卖阈值，为空使用 RSIAlert 配置
+	Hysteresis float64  `json:",optional"` // 回差，为空使用 RSIAlert 配置
+	Warmup     int      `json:",optional"` // 预热拉取的历史K线数量，为空使用 Klines 配置
+
+	Indicators []IndicatorConfig `json:",optional"` // 指标及告警条件，为空时只监控 RSI
+}
+
+// IndicatorConfig 监控项使用的指标，未设置的参数使用默认值
+type IndicatorConfig struct {
+	Type       string  `json:",optional"` // rsi/macd/ema_cross/bollinger/divergence
+	Period     int     `json:",optional"` // rsi/divergence 的 RSI 周期（默认使用监控项 Period）、bollinger 周期（默认20）
+	Fast       int     `json:",optional"` // macd 快线（默认12）、ema_cross 快线（默认20）
+	Slow       int     `json:",optional"` // macd 慢线（默认26）、ema_cross 慢线（默认50）
+	Signal     int     `json:",optional"` // macd 信号线，默认9
+	StdDev     float64 `json:",optional"` // bollinger 标准差倍数，默认2
+	Overbought float64 `json:",optional"` // rsi 超买阈值，为空使用监控项配置
+	Oversold   float64 `json:",optional"` // rsi 超卖阈值，为空使用监控项配置
+	Hysteresis float64 `json:",optional"` // rsi 回差；bollinger 为 %B 回差，默认0.2
+	Lookback   int     `json:",optional"` // divergence 两个摆动点最大间隔K线数，默认60
+	Strength   int     `json:",optional"` // divergence 摆动点左右各需的K线数，默认5
+}
+
+// ConfluenceRule 多周期共振规则：同一交易对 Intervals 中至少 MinMatches 个周期的 RSI 满足条件时，
+// 合并为一条告警
+type ConfluenceRule struct {
+	Name       string   `json:",optional"` // 规则名称
+	Symbols    []string `json:",optional"` // 交易对，为空匹配所有监控的交易对
+	Intervals  []string `json:",optional"` // 参与判断的周期，如 [4h, 1d, 1w]
+	MinMatches int      `json:",optional"` // 至少满足条件的周期数，默认全部
+	Condition  string   `json:",optional"` // oversold（RSI 低于阈值，默认）/ overbought（RSI 高于阈值）
+	Threshold  float64  `json:",optional"` // RSI 阈值，默认超卖30、超买70
+	Hysteresis float64  `json:",optional"` // 回差，满足的周期数回落后才会再次告警，默认2
+	KeepSingle bool     `json:",optional"` // 保留各周期单独的 RSI 告警，默认由共振告警替代
+}
+
+// PatternsConfig K 线形态检测配置
+type PatternsConfig struct {
+	Disabled bool          `json:",optional"` // 关闭形态检测
+	Rules    []PatternRule `json:",optional"` // 形态规则，为空时只在 4h/1d/1M 上检测十字星
+}
+
+// PatternRule 单个形态的检测规则，阈值为空时使用默认值
+type PatternRule struct {
+	Name        string   `json:",optional"` // hammer/shooting_star/bullish_engulfing/bearish_engulfing/inside_bar/three_white_soldiers/three_black_crows/doji/dragonfly_doji/gravestone_doji
+	Intervals   []string `json:",optional"` // 检测的周期，为空匹配所有周期
+	BodyPct     float64  `json:",optional"` // 实体占开盘价比例：doji 为上限，吞没/红三兵/三只乌鸦为下限，默认0.001
+	BodyRatio   float64  `json:",optional"` // 实体占振幅比例上限：锤子线/射击之星默认0.35，蜻蜓/墓碑十字星默认0.1
+	ShadowRatio float64  `json:",optional"` // 锤子线/射击之星长影线至少为实体的倍数（默认2）；蜻蜓/墓碑十字星短影线占振幅上限（默认0.1）
+}
+
+// VolumeConfig 成交量异动检测配置：收盘K线的成交额与最近 Lookback 根K线比较
+type VolumeConfig struct {
+	Disabled       bool     `json:",optional"` // 关闭成交量异动检测
+	Intervals      []string `json:",optional"` // 检测的周期，为空匹配所有周期
+	Lookback       int      `json:",optional"` // 滚动窗口的K线数，默认20
+	Method         string   `json:",optional"` // mean（均值/标准差，默认）/ median（中位数/MAD，不易受前期放量影响）
+	ZScore         float64  `json:",optional"` // 成交额 z-score 超过该值时告警，默认3
+	MinQuoteVolume float64  `json:",optional"` // 成交额低于该值 (USDT) 时不告警，过滤冷门交易对
+}
+
+// KlinesConfig 历史K线拉取配置，超过单次请求上限时按 endTime 向前分页
+type KlinesConfig struct {
+	BaseURL      string `json:",optional"` // Binance 合约 REST 地址，默认 https://fapi.binance.com
+	Warmup       int    `json:",optional"` // 预热拉取的历史K线数量，默认1000
+	CacheDir     string `json:",optional"` // 本地缓存目录，默认 ./storage/klines
+	CacheLimit   int    `json:",optional"` // 本地缓存每个交易对/周期最多保留的K线数量，默认5000，不少于 Warmup
+	DisableCache bool   `json:",optional"` // 关闭本地缓存，每次都从 REST 拉取
+}
+
+// PriceAlertsConfig 用户价格告警配置，告警通过 POST /notice/alerts 创建
+type PriceAlertsConfig struct {
+	Disabled    bool   `json:",optional"` // 关闭价格告警
+	Source      string `json:",optional"` // 价格来源: mark（标记价格，默认）/ last（最新成交价，来自 1m K线流）
+	MaxPerToken int    `json:",optional"` // 每个 token 最多的有效告警数，默认20
+	Cooldown    int    `json:",optional"` // 循环告警两次触发的最小间隔(秒)，默认300
+}
+
+// FundingConfig 资金费率（标记价格流）和持仓量（REST 轮询）监控配置
+type FundingConfig struct {
+	Disabled     bool     `json:",optional"` // 关闭资金费率/持仓量监控
+	Symbols      []string `json:",optional"` // 监控的交易对，为空时使用 Watchlist 中的交易对
+	HighRate     float64  `json:",optional"` // 资金费率上限，超过时告警，默认0.001（0.1%）
+	LowRate      float64  `json:",optional"` // 资金费率下限，低于时告警，默认-0.001
+	OIChangePct  float64  `json:",optional"` // 持仓量在窗口内变化超过该百分比时告警，默认5
+	OIWindow     int      `json:",optional"` // 持仓量变化的统计窗口(分钟)，默认60
+	PollInterval int      `json:",optional"` // 持仓量轮询间隔(秒)，默认60
+}
+
+// LiquidationConfig 清算监控配置，未设置的字段使用 margin_push 中的默认值
+type LiquidationConfig struct {
+	DisableAlert        bool               `json:",optional"` // 关闭大额清算实时告警
+	LargeOrderThreshold float64            `json:",optional"` // 单笔清算价值超过该值 (USDT) 时告警，默认10000
+	SymbolThresholds    map[string]float64 `json:",optional"` // 按交易对设置告警阈值 (USDT)，如 BTCUSDT: 500000
+	MaxAlertsPerHour    int                `json:",optional"` // 每小时最多推送的大额清算告警数，默认20
+	DisableCascade      bool               `json:",optional"` // 关闭连环清算告警
+	Cascades            []CascadeRule      `json:",optional"` // 连环清算规则，为空时使用默认的 1m/5m 规则
+}
+
+// CascadeRule 连环清算规则：滑动窗口内的清算价值或笔数达到阈值时告警
+type CascadeRule struct {
+	Window   int      `json:",optional"` // 滑动窗口(秒)，如 60、300
+	Market   bool     `json:",optional"` // true 统计全市场，false 按交易对分别统计
+	Symbols  []string `json:",optional"` // 按交易对统计时只监控这些交易对，为空表示全部
+	MinValue float64  `json:",optional"` // 窗口内清算价值达到该值 (USDT) 时告警，0 表示不按价值判断
+	MinCount int      `json:",optional"` // 窗口内清算笔数达到该值时告警，0 表示不按笔数判断
+}
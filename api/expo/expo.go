@@ -3,14 +3,27 @@ package expo
 import (
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"notice/api/model"
+	"notice/api/topic"
+
 	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
 )
 
 type Expo struct {
-	pushToken []expo.ExponentPushToken
-	client    *expo.PushClient
+	mu         sync.RWMutex
+	pushToken  []expo.ExponentPushToken
+	topics     map[expo.ExponentPushToken][]string // 每个 token 的订阅主题，为空表示订阅全部
+	owners     map[string]expo.ExponentPushToken   // 定向主题，只推送给所属 token（如用户的价格告警）
+	client     *expo.PushClient
+	store      TokenStore // token 持久化存储，为 nil 时仅保存在内存
+	host       string     // Expo 服务地址，用于查询推送回执
+	httpClient *http.Client
+	deliveries *deliveryTracker
 }
 
 var expoClient *Expo
@@ -23,14 +36,59 @@ func GetExpoClient() *Expo {
 }
 
 func NewClient() {
+	expoClient = newExpo(expo.DefaultHost)
+}
+
+// newExpo 创建指向指定 Expo 服务地址的客户端
+func newExpo(host string) *Expo {
 	// Create a new Expo SDK client
-	client := expo.NewPushClient(nil)
-	expoClient = &Expo{
-		pushToken: make([]expo.ExponentPushToken, 0),
-		client:    client,
+	client := expo.NewPushClient(&expo.ClientConfig{Host: host})
+	return &Expo{
+		pushToken:  make([]expo.ExponentPushToken, 0),
+		topics:     make(map[expo.ExponentPushToken][]string),
+		owners:     make(map[string]expo.ExponentPushToken),
+		client:     client,
+		host:       host,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		deliveries: newDeliveryTracker(),
 	}
 }
 
+// InitTokenStore 设置 token 持久化存储并加载已保存的 token
+func (e *Expo) InitTokenStore(store TokenStore) error {
+	tokens, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.store = store
+	for _, t := range tokens {
+		validtoken, err := e.validateToken(t.Token)
+		if err != nil {
+			log.Printf("跳过无效的已保存 Token %s: %v", t.Token, err)
+			continue
+		}
+		if !containsToken(e.pushToken, validtoken) {
+			e.pushToken = append(e.pushToken, validtoken)
+			e.topics[validtoken] = topic.Parse(t.Topics)
+		}
+	}
+	log.Printf("已加载 %d 个推送 Token", len(e.pushToken))
+	return nil
+}
+
+func containsToken(tokens []expo.ExponentPushToken, token expo.ExponentPushToken) bool {
+	for _, existingToken := range tokens {
+		if existingToken == token {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Expo) validateToken(token string) (expo.ExponentPushToken, error) {
 	validtoken, err := expo.NewExponentPushToken(token)
 	if err != nil {
@@ -40,24 +98,46 @@ func (e *Expo) validateToken(token string) (expo.ExponentPushToken, error) {
 }
 
 func (e *Expo) AddToken(token string) error {
+	return e.AddTokenWithDevice(token, "")
+}
+
+// AddTokenWithDevice 添加 token 并记录设备信息，topics 为订阅主题（为空表示订阅全部）
+func (e *Expo) AddTokenWithDevice(token, deviceInfo string, topics ...string) error {
 	validtoken, err := e.validateToken(token)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", err)
 	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	// 检查是否已存在该 token
-	for _, existingToken := range e.pushToken {
-		if existingToken == validtoken {
-			return fmt.Errorf("token already exists")
+	if containsToken(e.pushToken, validtoken) {
+		return fmt.Errorf("token already exists")
+	}
+
+	if e.store != nil {
+		err := e.store.Save(model.PushToken{
+			Token:      string(validtoken),
+			DeviceInfo: deviceInfo,
+			IsActive:   true,
+			LastUsed:   time.Now(),
+			Topics:     strings.Join(topics, ","),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to persist token: %w", err)
 		}
 	}
 
 	e.pushToken = append(e.pushToken, validtoken)
+	e.topics[validtoken] = topics
 	return nil
 }
 
 // GetTokenCount 返回当前 token 数量
 func (e *Expo) GetTokenCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	return len(e.pushToken)
 }
 
@@ -68,10 +148,19 @@ func (e *Expo) RemoveToken(token string) error {
 		return fmt.Errorf("invalid token: %w", err)
 	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	for i, existingToken := range e.pushToken {
 		if existingToken == validtoken {
+			if e.store != nil {
+				if err := e.store.Remove(string(validtoken)); err != nil {
+					return fmt.Errorf("failed to remove persisted token: %w", err)
+				}
+			}
 			// 移除该 token
 			e.pushToken = append(e.pushToken[:i], e.pushToken[i+1:]...)
+			delete(e.topics, validtoken)
 			return nil
 		}
 	}
@@ -81,7 +170,31 @@ func (e *Expo) RemoveToken(token string) error {
 
 // GetTokens 返回所有 token（用于调试）
 func (e *Expo) GetTokens() []expo.ExponentPushToken {
-	return e.pushToken
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	tokens := make([]expo.ExponentPushToken, len(e.pushToken))
+	copy(tokens, e.pushToken)
+	return tokens
+}
+
+// Name 渠道名称（实现 notification.Notifier 接口）
+func (e *Expo) Name() string {
+	return "expo"
+}
+
+// Notify 向订阅了该主题的 token 推送通知（实现 notification.Notifier 接口）
+func (e *Expo) Notify(title, message, source string) error {
+	return e.SendToTopic(message, title, source, 3)
+}
+
+// NotifyWithRetry 以指定重试次数推送通知
+func (e *Expo) NotifyWithRetry(title, message, source string, maxRetries int) error {
+	return e.SendToTopic(message, title, source, maxRetries)
+}
+
+// Available 没有注册任何 token 时不参与通知分发
+func (e *Expo) Available() bool {
+	return e.GetTokenCount() > 0
 }
 
 func (e *Expo) Send(message string) error {
@@ -97,74 +210,16 @@ func (e *Expo) SendWithRetry(message string, maxRetries int) error {
 }
 
 func (e *Expo) SendWithCustomTitleAndRetry(message, title string, maxRetries int) error {
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		log.Printf("推送尝试 %d/%d", attempt, maxRetries)
-
-		// Publish message
-		response, err := e.client.Publish(
-			&expo.PushMessage{
-				To:         e.pushToken,
-				Body:       message,
-				Data:       map[string]string{"withSome": "data", "format": "html"},
-				Sound:      "default",
-				Title:      title,
-				Priority:   expo.HighPriority,
-				TTLSeconds: 0,
-				ChannelID:  "default",
-			},
-		)
-		// Check network/client errors
-		if err != nil {
-			lastErr = fmt.Errorf("网络错误 (尝试 %d): %w", attempt, err)
-			log.Printf("推送失败: %v", lastErr)
-
-			if attempt < maxRetries {
-				waitTime := time.Duration(attempt) * 2 * time.Second
-				log.Printf("等待 %v 后重试...", waitTime)
-				time.Sleep(waitTime)
-				continue
-			}
-			return lastErr
-		}
-
-		// Print detailed response for debugging
-		log.Printf("推送响应: Status=%s, ID=%s", response.Status, response.ID)
-		if response.Message != "" {
-			log.Printf("响应消息: %s", response.Message)
-		}
-
-		// Check if push was accepted by Expo
-		if response.Status == "ok" {
-			log.Printf("推送成功提交到 Expo 服务器")
-			return nil
-		}
-
-		// Handle specific error cases
-		if response.Status == "error" {
-			switch response.Details["error"] {
-			case "DeviceNotRegistered":
-				return fmt.Errorf("设备未注册，Token 可能已失效: %s", response.Message)
-			case "MessageTooBig":
-				return fmt.Errorf("消息过大: %s", response.Message)
-			case "MessageRateExceeded":
-				lastErr = fmt.Errorf("消息频率超限 (尝试 %d): %s", attempt, response.Message)
-				log.Printf("推送被限流: %v", lastErr)
-			default:
-				return fmt.Errorf("推送错误: %s (详情: %v)", response.Message, response.Details)
-			}
-		}
-
-		// For rate limiting, wait and retry
-		if attempt < maxRetries {
-			waitTime := time.Duration(attempt) * 5 * time.Second // 更长的等待时间
-			log.Printf("等待 %v 后重试...", waitTime)
-			time.Sleep(waitTime)
-		}
+	tokens := e.GetTokens()
+	if len(tokens) == 0 {
+		return fmt.Errorf("没有已注册的推送 Token")
 	}
 
-	return fmt.Errorf("推送失败，已重试 %d 次: %v", maxRetries, lastErr)
+	report := e.Broadcast(tokens, message, title, maxRetries)
+	if report.Accepted == 0 {
+		return fmt.Errorf("推送失败，已重试 %d 次: %s", maxRetries, report.LastError)
+	}
+	return nil
 }
 
 // CheckToken 检查推送 Token 是否仍然有效
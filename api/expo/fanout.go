@@ -0,0 +1,170 @@
+package expo
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+const (
+	// 单次推送请求最多包含的消息数量（Expo 限制）
+	maxMessagesPerRequest = 100
+	// 同时发送的分片数量
+	maxConcurrentChunks = 4
+)
+
+// ChunkResult 单个分片的发送结果
+type ChunkResult struct {
+	Index       int    `json:"index"`
+	Tokens      int    `json:"tokens"`
+	Accepted    int    `json:"accepted"`
+	Failed      int    `json:"failed"`
+	Deactivated int    `json:"deactivated"`
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error,omitempty"`
+}
+
+// SendReport 一次广播推送的汇总结果
+type SendReport struct {
+	Total       int           `json:"total"`
+	Accepted    int           `json:"accepted"`
+	Failed      int           `json:"failed"`
+	Deactivated int           `json:"deactivated"`
+	LastError   string        `json:"last_error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Chunks      []ChunkResult `json:"chunks"`
+}
+
+// Broadcast 将消息按 Expo 限制分片，并发推送到所有 token，并汇总每个分片的结果。
+// 单个分片失败不会影响其他分片的发送。
+func (e *Expo) Broadcast(tokens []expo.ExponentPushToken, message, title string, maxRetries int) *SendReport {
+	start := time.Now()
+
+	var chunks [][]expo.ExponentPushToken
+	for i := 0; i < len(tokens); i += maxMessagesPerRequest {
+		end := i + maxMessagesPerRequest
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		chunks = append(chunks, tokens[i:end])
+	}
+
+	results := make([]ChunkResult, len(chunks))
+	sem := make(chan struct{}, maxConcurrentChunks)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []expo.ExponentPushToken) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.sendChunk(i, chunk, message, title, maxRetries)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	report := &SendReport{Total: len(tokens), Chunks: results}
+	for _, r := range results {
+		report.Accepted += r.Accepted
+		report.Failed += r.Failed
+		report.Deactivated += r.Deactivated
+		if r.Error != "" {
+			report.LastError = r.Error
+		}
+	}
+	report.Duration = time.Since(start)
+
+	log.Printf("推送完成: 总数=%d 成功=%d 失败=%d 失效=%d 分片=%d 耗时=%v",
+		report.Total, report.Accepted, report.Failed, report.Deactivated, len(chunks), report.Duration)
+	return report
+}
+
+// sendChunk 发送单个分片，网络错误和限流时只重试本分片中未成功的 token
+func (e *Expo) sendChunk(index int, tokens []expo.ExponentPushToken, message, title string, maxRetries int) ChunkResult {
+	result := ChunkResult{Index: index, Tokens: len(tokens)}
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries && len(tokens) > 0; attempt++ {
+		result.Attempts = attempt
+		log.Printf("分片 %d 推送尝试 %d/%d (Token 数: %d)", index, attempt, maxRetries, len(tokens))
+
+		// 每个 token 一条消息，保证返回的推送票据与 token 一一对应
+		messages := make([]expo.PushMessage, 0, len(tokens))
+		for _, token := range tokens {
+			messages = append(messages, expo.PushMessage{
+				To:         []expo.ExponentPushToken{token},
+				Body:       message,
+				Data:       map[string]string{"withSome": "data", "format": "html"},
+				Sound:      "default",
+				Title:      title,
+				Priority:   expo.HighPriority,
+				TTLSeconds: 0,
+				ChannelID:  "default",
+			})
+		}
+
+		responses, err := e.client.PublishMultiple(messages)
+		// Check network/client errors
+		if err != nil {
+			lastErr = fmt.Errorf("网络错误 (分片 %d 尝试 %d): %w", index, attempt, err)
+			log.Printf("推送失败: %v", lastErr)
+
+			if attempt < maxRetries {
+				waitTime := time.Duration(attempt) * 2 * time.Second
+				log.Printf("等待 %v 后重试...", waitTime)
+				time.Sleep(waitTime)
+			}
+			continue
+		}
+
+		// 逐个 token 处理推送票据，单个 token 失败不影响其他 token
+		var retryTokens []expo.ExponentPushToken
+		for i, response := range responses {
+			token := tokens[i]
+			if response.Status == expo.SuccessStatus {
+				result.Accepted++
+				e.trackTicket(token, response.ID, title)
+				continue
+			}
+
+			log.Printf("推送被拒绝: Token=%s, 消息=%s, 详情=%v", token, response.Message, response.Details)
+			switch response.Details["error"] {
+			case expo.ErrorDeviceNotRegistered:
+				result.Failed++
+				result.Deactivated++
+				e.recordFailure(token, title, response.Message)
+				e.deactivateToken(token)
+			case expo.ErrorMessageRateExceeded:
+				lastErr = fmt.Errorf("消息频率超限 (分片 %d 尝试 %d): %s", index, attempt, response.Message)
+				retryTokens = append(retryTokens, token)
+			default:
+				result.Failed++
+				lastErr = fmt.Errorf("推送错误: %s (详情: %v)", response.Message, response.Details)
+				e.recordFailure(token, title, response.Message)
+			}
+		}
+		tokens = retryTokens
+
+		// For rate limiting, wait and retry
+		if len(tokens) > 0 && attempt < maxRetries {
+			waitTime := time.Duration(attempt) * 5 * time.Second // 更长的等待时间
+			log.Printf("分片 %d 有 %d 个 Token 被限流，等待 %v 后重试...", index, len(tokens), waitTime)
+			time.Sleep(waitTime)
+		}
+	}
+
+	// 重试用尽后仍未成功的 token 计为失败
+	for _, token := range tokens {
+		result.Failed++
+		if lastErr != nil {
+			e.recordFailure(token, title, lastErr.Error())
+		}
+	}
+	if lastErr != nil && (len(tokens) > 0 || result.Accepted == 0) {
+		result.Error = lastErr.Error()
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package expo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+func TestBroadcastChunks(t *testing.T) {
+	failing := "ExponentPushToken[token-100]"
+	var requests, oversized int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var messages []expo.PushMessage
+		if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
+			t.Errorf("decode push request: %v", err)
+			return
+		}
+		if len(messages) > maxMessagesPerRequest {
+			atomic.AddInt32(&oversized, 1)
+		}
+		// 包含 failing token 的分片整体返回服务端错误
+		for _, m := range messages {
+			if string(m.To[0]) == failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+		data := make([]map[string]string, 0, len(messages))
+		for _, m := range messages {
+			data = append(data, map[string]string{"status": "ok", "id": "ticket-" + string(m.To[0])})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer srv.Close()
+
+	tokens := make([]expo.ExponentPushToken, 250)
+	for i := range tokens {
+		tokens[i] = expo.ExponentPushToken(fmt.Sprintf("ExponentPushToken[token-%d]", i))
+	}
+
+	client := newExpo(srv.URL)
+	report := client.Broadcast(tokens, "test", "title", 1)
+
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if got := atomic.LoadInt32(&oversized); got != 0 {
+		t.Errorf("%d requests exceeded %d messages", got, maxMessagesPerRequest)
+	}
+	if report.Total != 250 || report.Accepted != 150 || report.Failed != 100 {
+		t.Errorf("report = total %d accepted %d failed %d, want 250/150/100",
+			report.Total, report.Accepted, report.Failed)
+	}
+	if len(report.Chunks) != 3 || report.Chunks[1].Error == "" || report.Chunks[2].Accepted != 50 {
+		t.Errorf("chunks = %+v, want chunk 1 failed and chunk 2 with 50 accepted", report.Chunks)
+	}
+	if report.LastError == "" {
+		t.Error("LastError should record the failing chunk")
+	}
+}
@@ -0,0 +1,260 @@
+package expo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+const (
+	// 单次 getReceipts 请求最多查询的票据数量（Expo 限制）
+	maxReceiptIDsPerRequest = 1000
+	// 每个 token 保留的投递记录数量
+	maxHistoryPerToken = 50
+	// 回执超过该时间仍未返回则放弃查询（Expo 只保留 24 小时）
+	receiptExpiry = 24 * time.Hour
+)
+
+// 投递状态
+const (
+	DeliveryPending = "pending" // 已提交到 Expo，等待回执
+	DeliveryOK      = "ok"      // 回执确认已投递到 APNs/FCM
+	DeliveryError   = "error"   // 推送或回执返回错误
+)
+
+// DeliveryRecord 单个 token 的一次推送投递记录
+type DeliveryRecord struct {
+	TicketID  string    `json:"ticket_id,omitempty"`
+	Token     string    `json:"token"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// deliveryTracker 跟踪待查询回执的推送票据和每个 token 的投递历史
+type deliveryTracker struct {
+	mu      sync.Mutex
+	pending map[string]*DeliveryRecord   // key: ticket ID
+	history map[string][]*DeliveryRecord // key: token
+}
+
+func newDeliveryTracker() *deliveryTracker {
+	return &deliveryTracker{
+		pending: make(map[string]*DeliveryRecord),
+		history: make(map[string][]*DeliveryRecord),
+	}
+}
+
+func (t *deliveryTracker) add(record *DeliveryRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record.TicketID != "" && record.Status == DeliveryPending {
+		t.pending[record.TicketID] = record
+	}
+	records := append(t.history[record.Token], record)
+	if len(records) > maxHistoryPerToken {
+		records = records[len(records)-maxHistoryPerToken:]
+	}
+	t.history[record.Token] = records
+}
+
+// trackTicket 记录推送票据，等待后续查询回执
+func (e *Expo) trackTicket(token expo.ExponentPushToken, ticketID, title string) {
+	e.deliveries.add(&DeliveryRecord{
+		TicketID: ticketID,
+		Token:    string(token),
+		Title:    title,
+		Status:   DeliveryPending,
+		SentAt:   time.Now(),
+	})
+}
+
+// recordFailure 记录推送时即被拒绝的投递
+func (e *Expo) recordFailure(token expo.ExponentPushToken, title, errMsg string) {
+	now := time.Now()
+	e.deliveries.add(&DeliveryRecord{
+		Token:     string(token),
+		Title:     title,
+		Status:    DeliveryError,
+		Error:     errMsg,
+		SentAt:    now,
+		CheckedAt: now,
+	})
+}
+
+// deactivateToken 将失效的 token 从推送列表中移除并在存储中标记为不活跃
+func (e *Expo) deactivateToken(token expo.ExponentPushToken) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, existingToken := range e.pushToken {
+		if existingToken == token {
+			e.pushToken = append(e.pushToken[:i], e.pushToken[i+1:]...)
+			delete(e.topics, token)
+			break
+		}
+	}
+	if e.store != nil {
+		if err := e.store.Deactivate(string(token)); err != nil {
+			log.Printf("标记 Token 失效失败: %s, %v", token, err)
+			return
+		}
+	}
+	log.Printf("Token 已失效并被移除: %s", token)
+}
+
+// GetDeliveryHistory 返回指定 token 的投递历史（最新的在最后）
+func (e *Expo) GetDeliveryHistory(token string) []DeliveryRecord {
+	e.deliveries.mu.Lock()
+	defer e.deliveries.mu.Unlock()
+
+	records := e.deliveries.history[token]
+	result := make([]DeliveryRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, *r)
+	}
+	return result
+}
+
+// GetAllDeliveryHistory 返回所有 token 的投递历史
+func (e *Expo) GetAllDeliveryHistory() map[string][]DeliveryRecord {
+	e.deliveries.mu.Lock()
+	defer e.deliveries.mu.Unlock()
+
+	result := make(map[string][]DeliveryRecord, len(e.deliveries.history))
+	for token, records := range e.deliveries.history {
+		list := make([]DeliveryRecord, 0, len(records))
+		for _, r := range records {
+			list = append(list, *r)
+		}
+		result[token] = list
+	}
+	return result
+}
+
+// GetPendingReceiptCount 返回等待回执的票据数量
+func (e *Expo) GetPendingReceiptCount() int {
+	e.deliveries.mu.Lock()
+	defer e.deliveries.mu.Unlock()
+	return len(e.deliveries.pending)
+}
+
+// StartReceiptPoller 启动后台回执轮询
+// interval 为轮询间隔，delay 为推送后等待多久再查询回执（Expo 建议约 15 分钟）
+func (e *Expo) StartReceiptPoller(interval, delay time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := e.PollReceipts(delay); err != nil {
+				log.Printf("查询推送回执失败: %v", err)
+			}
+		}
+	}()
+}
+
+// receiptResponse getReceipts 接口响应
+type receiptResponse struct {
+	Data map[string]struct {
+		Status  string            `json:"status"`
+		Message string            `json:"message"`
+		Details map[string]string `json:"details"`
+	} `json:"data"`
+	Errors []map[string]string `json:"errors"`
+}
+
+// PollReceipts 查询所有发送时间早于 delay 的推送票据回执
+func (e *Expo) PollReceipts(delay time.Duration) error {
+	now := time.Now()
+	var ids []string
+
+	e.deliveries.mu.Lock()
+	for id, record := range e.deliveries.pending {
+		if now.Sub(record.SentAt) > receiptExpiry {
+			record.Status = DeliveryError
+			record.Error = "receipt expired"
+			record.CheckedAt = now
+			delete(e.deliveries.pending, id)
+			continue
+		}
+		if now.Sub(record.SentAt) >= delay {
+			ids = append(ids, id)
+		}
+	}
+	e.deliveries.mu.Unlock()
+
+	for start := 0; start < len(ids); start += maxReceiptIDsPerRequest {
+		end := start + maxReceiptIDsPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := e.fetchReceipts(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchReceipts 调用 Expo getReceipts 接口并更新投递状态
+func (e *Expo) fetchReceipts(ids []string) error {
+	body, err := json.Marshal(map[string][]string{"ids": ids})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s%s/push/getReceipts", e.host, expo.DefaultBaseAPIURL)
+	resp, err := e.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("getReceipts status=%d", resp.StatusCode)
+	}
+
+	var result receiptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("getReceipts errors: %v", result.Errors)
+	}
+
+	now := time.Now()
+	var deadTokens []expo.ExponentPushToken
+
+	e.deliveries.mu.Lock()
+	for id, receipt := range result.Data {
+		record, ok := e.deliveries.pending[id]
+		if !ok {
+			continue
+		}
+		delete(e.deliveries.pending, id)
+		record.CheckedAt = now
+		if receipt.Status == expo.SuccessStatus {
+			record.Status = DeliveryOK
+			continue
+		}
+		record.Status = DeliveryError
+		record.Error = receipt.Message
+		if receipt.Details["error"] == expo.ErrorDeviceNotRegistered {
+			deadTokens = append(deadTokens, expo.ExponentPushToken(record.Token))
+		}
+	}
+	e.deliveries.mu.Unlock()
+
+	for _, token := range deadTokens {
+		e.deactivateToken(token)
+	}
+	return nil
+}
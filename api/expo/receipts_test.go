@@ -0,0 +1,123 @@
+package expo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+// newFakeExpoServer 模拟 Expo 推送接口：deadOnSend 推送时即返回 DeviceNotRegistered，
+// deadOnReceipt 推送成功但回执返回 DeviceNotRegistered
+func newFakeExpoServer(t *testing.T, deadOnSend, deadOnReceipt string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/--/api/v2/push/send":
+			var messages []expo.PushMessage
+			if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
+				t.Errorf("decode push request: %v", err)
+				return
+			}
+			data := make([]map[string]interface{}, 0, len(messages))
+			for _, m := range messages {
+				token := string(m.To[0])
+				if token == deadOnSend {
+					data = append(data, map[string]interface{}{
+						"status":  "error",
+						"message": "not a registered push notification recipient",
+						"details": map[string]string{"error": expo.ErrorDeviceNotRegistered},
+					})
+					continue
+				}
+				data = append(data, map[string]interface{}{"status": "ok", "id": "ticket-" + token})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		case "/--/api/v2/push/getReceipts":
+			var req struct {
+				IDs []string `json:"ids"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode receipts request: %v", err)
+				return
+			}
+			data := make(map[string]interface{})
+			for _, id := range req.IDs {
+				if id == "ticket-"+deadOnReceipt {
+					data[id] = map[string]interface{}{
+						"status":  "error",
+						"message": "device not registered",
+						"details": map[string]string{"error": expo.ErrorDeviceNotRegistered},
+					}
+					continue
+				}
+				data[id] = map[string]interface{}{"status": "ok"}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestReceiptPruning(t *testing.T) {
+	tokens := make([]string, 3)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("ExponentPushToken[token-%d]", i)
+	}
+	srv := newFakeExpoServer(t, tokens[0], tokens[1])
+	defer srv.Close()
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "push_tokens.json"))
+	client := newExpo(srv.URL)
+	if err := client.InitTokenStore(store); err != nil {
+		t.Fatalf("InitTokenStore() error = %v", err)
+	}
+	for _, token := range tokens {
+		if err := client.AddToken(token); err != nil {
+			t.Fatalf("AddToken(%s) error = %v", token, err)
+		}
+	}
+
+	// 单个失效 token 不应导致整批推送失败
+	if err := client.SendWithCustomTitle("test", "title"); err != nil {
+		t.Fatalf("SendWithCustomTitle() error = %v", err)
+	}
+	if got := client.GetTokenCount(); got != 2 {
+		t.Fatalf("推送后 token 数 = %d, want 2", got)
+	}
+	if got := client.GetPendingReceiptCount(); got != 2 {
+		t.Fatalf("待查询回执数 = %d, want 2", got)
+	}
+
+	if err := client.PollReceipts(0); err != nil {
+		t.Fatalf("PollReceipts() error = %v", err)
+	}
+	if got := client.GetTokens(); len(got) != 1 || string(got[0]) != tokens[2] {
+		t.Fatalf("回执处理后 tokens = %v, want [%s]", got, tokens[2])
+	}
+	if got := client.GetPendingReceiptCount(); got != 0 {
+		t.Errorf("回执处理后待查询数 = %d, want 0", got)
+	}
+
+	history := client.GetDeliveryHistory(tokens[2])
+	if len(history) != 1 || history[0].Status != DeliveryOK {
+		t.Errorf("GetDeliveryHistory(%s) = %+v, want one ok record", tokens[2], history)
+	}
+	history = client.GetDeliveryHistory(tokens[1])
+	if len(history) != 1 || history[0].Status != DeliveryError {
+		t.Errorf("GetDeliveryHistory(%s) = %+v, want one error record", tokens[1], history)
+	}
+
+	// 失效 token 在存储中保留但标记为不活跃
+	active, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(active) != 1 || active[0].Token != tokens[2] {
+		t.Errorf("Load() = %+v, want only %s active", active, tokens[2])
+	}
+}
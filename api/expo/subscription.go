@@ -0,0 +1,121 @@
+package expo
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"notice/api/topic"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+// SetTopics 更新 token 的订阅主题，topics 为空表示订阅全部消息
+func (e *Expo) SetTopics(token string, topics []string) error {
+	validtoken, err := e.validateToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !containsToken(e.pushToken, validtoken) {
+		return fmt.Errorf("token not found")
+	}
+	if e.store != nil {
+		if err := e.store.UpdateTopics(string(validtoken), strings.Join(topics, ",")); err != nil {
+			return fmt.Errorf("failed to persist topics: %w", err)
+		}
+	}
+	e.topics[validtoken] = topics
+	return nil
+}
+
+// GetTopics 返回 token 的订阅主题
+func (e *Expo) GetTopics(token string) ([]string, error) {
+	validtoken, err := e.validateToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if !containsToken(e.pushToken, validtoken) {
+		return nil, fmt.Errorf("token not found")
+	}
+	topics := make([]string, len(e.topics[validtoken]))
+	copy(topics, e.topics[validtoken])
+	return topics, nil
+}
+
+// SetTopicOwner 将主题设为定向主题：该主题及其子主题的消息只推送给 token，不再按订阅匹配
+func (e *Expo) SetTopicOwner(t, token string) error {
+	validtoken, err := e.validateToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.owners[topic.Normalize(t)] = validtoken
+	return nil
+}
+
+// RemoveTopicOwner 取消定向主题
+func (e *Expo) RemoveTopicOwner(t string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.owners, topic.Normalize(t))
+}
+
+// HasTopicOwner 主题或其父主题是否为定向主题
+func (e *Expo) HasTopicOwner(t string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, p := range topic.Prefixes(topic.Normalize(t)) {
+		if _, ok := e.owners[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokensForTopic 返回订阅了指定主题的 token；定向主题只返回所属 token
+func (e *Expo) GetTokensForTopic(t string) []expo.ExponentPushToken {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, p := range topic.Prefixes(topic.Normalize(t)) {
+		if owner, ok := e.owners[p]; ok {
+			if containsToken(e.pushToken, owner) {
+				return []expo.ExponentPushToken{owner}
+			}
+			return nil
+		}
+	}
+
+	var tokens []expo.ExponentPushToken
+	for _, token := range e.pushToken {
+		if topic.MatchAny(e.topics[token], t) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// SendToTopic 只向订阅了该主题的 token 推送消息；没有订阅者时直接返回
+func (e *Expo) SendToTopic(message, title, t string, maxRetries int) error {
+	tokens := e.GetTokensForTopic(t)
+	if len(tokens) == 0 {
+		log.Printf("主题 %s 没有订阅的 Token，跳过推送", t)
+		return nil
+	}
+
+	report := e.Broadcast(tokens, message, title, maxRetries)
+	if report.Accepted == 0 {
+		return fmt.Errorf("推送失败，已重试 %d 次: %s", maxRetries, report.LastError)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package expo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+func TestTopicSubscriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "push_tokens.json")
+	client := newExpo(expo.DefaultHost)
+	if err := client.InitTokenStore(NewFileTokenStore(path)); err != nil {
+		t.Fatalf("InitTokenStore() error = %v", err)
+	}
+
+	all := "ExponentPushToken[all]"
+	btc := "ExponentPushToken[btc]"
+	news := "ExponentPushToken[news]"
+	client.AddToken(all)
+	client.AddTokenWithDevice(btc, "", "rsi:btcusdt:4h", "liquidation")
+	client.AddTokenWithDevice(news, "", "news")
+
+	tests := []struct {
+		topic string
+		want  []expo.ExponentPushToken
+	}{
+		{"rsi:btcusdt:4h", []expo.ExponentPushToken{expo.ExponentPushToken(all), expo.ExponentPushToken(btc)}},
+		{"rsi:ethusdt:4h", []expo.ExponentPushToken{expo.ExponentPushToken(all)}},
+		{"news", []expo.ExponentPushToken{expo.ExponentPushToken(all), expo.ExponentPushToken(news)}},
+	}
+	for _, tt := range tests {
+		if got := client.GetTokensForTopic(tt.topic); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetTokensForTopic(%q) = %v, want %v", tt.topic, got, tt.want)
+		}
+	}
+
+	if err := client.SetTopics(news, []string{"news", "doji"}); err != nil {
+		t.Fatalf("SetTopics() error = %v", err)
+	}
+
+	// 重启后订阅从存储恢复
+	restarted := newExpo(expo.DefaultHost)
+	if err := restarted.InitTokenStore(NewFileTokenStore(path)); err != nil {
+		t.Fatalf("InitTokenStore() error = %v", err)
+	}
+	got, err := restarted.GetTopics(news)
+	if err != nil || !reflect.DeepEqual(got, []string{"news", "doji"}) {
+		t.Errorf("GetTopics() = %v, %v, want [news doji]", got, err)
+	}
+	if got, _ := restarted.GetTopics(all); len(got) != 0 {
+		t.Errorf("GetTopics(all) = %v, want empty", got)
+	}
+}
+
+func TestTopicOwner(t *testing.T) {
+	client := newExpo(expo.DefaultHost)
+	all := "ExponentPushToken[all]"
+	owner := "ExponentPushToken[owner]"
+	client.AddToken(all)
+	client.AddTokenWithDevice(owner, "", "news")
+
+	if err := client.SetTopicOwner("price:btcusdt:1", owner); err != nil {
+		t.Fatalf("SetTopicOwner() error = %v", err)
+	}
+	want := []expo.ExponentPushToken{expo.ExponentPushToken(owner)}
+	if got := client.GetTokensForTopic("price:btcusdt:1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("owned topic should only go to its owner, got %v", got)
+	}
+	if got := client.GetTokensForTopic("price:btcusdt:2"); !reflect.DeepEqual(got, []expo.ExponentPushToken{expo.ExponentPushToken(all)}) {
+		t.Errorf("other topics should match subscriptions, got %v", got)
+	}
+	if !client.HasTopicOwner("PRICE:BTCUSDT:1") || client.HasTopicOwner("price:btcusdt:2") {
+		t.Error("HasTopicOwner should only report owned topics")
+	}
+
+	client.RemoveTopicOwner("price:btcusdt:1")
+	if client.HasTopicOwner("price:btcusdt:1") {
+		t.Error("removed owner should no longer be reported")
+	}
+	if got := client.GetTokensForTopic("price:btcusdt:1"); len(got) != 1 || got[0] != expo.ExponentPushToken(all) {
+		t.Errorf("removed owner should fall back to subscriptions, got %v", got)
+	}
+}
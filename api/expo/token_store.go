@@ -0,0 +1,215 @@
+package expo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"notice/api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenStore 推送令牌持久化接口
+type TokenStore interface {
+	// Load 加载所有活跃的 token
+	Load() ([]model.PushToken, error)
+	// Save 保存 token（已存在则更新）
+	Save(token model.PushToken) error
+	// Remove 删除 token
+	Remove(token string) error
+	// Deactivate 将 token 标记为失效（如设备已卸载应用）
+	Deactivate(token string) error
+	// UpdateTopics 更新 token 的订阅主题（逗号分隔）
+	UpdateTopics(token, topics string) error
+}
+
+// dbTokenStore 基于 Postgres 的 token 存储
+type dbTokenStore struct {
+	db *gorm.DB
+}
+
+// NewDBTokenStore 创建基于数据库的 token 存储
+func NewDBTokenStore(db *gorm.DB) TokenStore {
+	return &dbTokenStore{db: db}
+}
+
+func (s *dbTokenStore) Load() ([]model.PushToken, error) {
+	var tokens []model.PushToken
+	if err := s.db.Where("is_active = ?", true).Order("id").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to load push tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *dbTokenStore) Save(token model.PushToken) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"device_info", "is_active", "last_used", "topics", "updated_at", "deleted_at"}),
+	}).Create(&token).Error
+}
+
+func (s *dbTokenStore) Remove(token string) error {
+	// 硬删除，保证唯一索引下可以重新注册
+	return s.db.Unscoped().Where("token = ?", token).Delete(&model.PushToken{}).Error
+}
+
+func (s *dbTokenStore) Deactivate(token string) error {
+	return s.db.Model(&model.PushToken{}).Where("token = ?", token).Update("is_active", false).Error
+}
+
+func (s *dbTokenStore) UpdateTopics(token, topics string) error {
+	return s.db.Model(&model.PushToken{}).Where("token = ?", token).Update("topics", topics).Error
+}
+
+// fileTokenStore 基于本地文件的 token 存储（未配置数据库时使用）
+type fileTokenStore struct {
+	filePath string
+	mutex    sync.Mutex
+}
+
+// NewFileTokenStore 创建基于本地 JSON 文件的 token 存储
+func NewFileTokenStore(filePath string) TokenStore {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		fmt.Printf("Failed to create token storage directory: %v\n", err)
+	}
+	return &fileTokenStore{filePath: filePath}
+}
+
+func (s *fileTokenStore) Load() ([]model.PushToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]model.PushToken, 0, len(tokens))
+	for _, t := range tokens {
+		if t.IsActive {
+			active = append(active, t)
+		}
+	}
+	return active, nil
+}
+
+func (s *fileTokenStore) Save(token model.PushToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token.UpdatedAt = now
+	for i := range tokens {
+		if tokens[i].Token == token.Token {
+			token.ID = tokens[i].ID
+			token.CreatedAt = tokens[i].CreatedAt
+			tokens[i] = token
+			return s.write(tokens)
+		}
+	}
+
+	token.ID = uint(len(tokens) + 1)
+	for _, t := range tokens {
+		if t.ID >= token.ID {
+			token.ID = t.ID + 1
+		}
+	}
+	token.CreatedAt = now
+	tokens = append(tokens, token)
+	return s.write(tokens)
+}
+
+func (s *fileTokenStore) Remove(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range tokens {
+		if tokens[i].Token == token {
+			tokens = append(tokens[:i], tokens[i+1:]...)
+			return s.write(tokens)
+		}
+	}
+	return nil
+}
+
+func (s *fileTokenStore) Deactivate(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range tokens {
+		if tokens[i].Token == token {
+			tokens[i].IsActive = false
+			tokens[i].UpdatedAt = time.Now()
+			return s.write(tokens)
+		}
+	}
+	return nil
+}
+
+func (s *fileTokenStore) UpdateTopics(token, topics string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	for i := range tokens {
+		if tokens[i].Token == token {
+			tokens[i].Topics = topics
+			tokens[i].UpdatedAt = time.Now()
+			return s.write(tokens)
+		}
+	}
+	return fmt.Errorf("token not found")
+}
+
+// read 从文件读取 token 列表
+func (s *fileTokenStore) read() ([]model.PushToken, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return []model.PushToken{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return []model.PushToken{}, nil
+	}
+
+	var tokens []model.PushToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal push tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// write 写入 token 列表到文件
+func (s *fileTokenStore) write(tokens []model.PushToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filePath, data, 0o644)
+}
@@ -0,0 +1,51 @@
+package expo
+
+import (
+	"path/filepath"
+	"testing"
+
+	expo "github.com/oliveroneill/exponent-server-sdk-golang/sdk"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "push_tokens.json")
+	store := NewFileTokenStore(path)
+
+	client := newExpo(expo.DefaultHost)
+	if err := client.InitTokenStore(store); err != nil {
+		t.Fatalf("InitTokenStore() error = %v", err)
+	}
+
+	tokenA := "ExponentPushToken[aaaaaaaaaaaaaaaaaaaaaa]"
+	tokenB := "ExponentPushToken[bbbbbbbbbbbbbbbbbbbbbb]"
+	if err := client.AddTokenWithDevice(tokenA, "iPhone 15"); err != nil {
+		t.Fatalf("AddTokenWithDevice() error = %v", err)
+	}
+	if err := client.AddToken(tokenB); err != nil {
+		t.Fatalf("AddToken() error = %v", err)
+	}
+	if err := client.AddToken(tokenA); err == nil {
+		t.Error("AddToken() 重复添加应返回错误")
+	}
+	if err := client.RemoveToken(tokenB); err != nil {
+		t.Fatalf("RemoveToken() error = %v", err)
+	}
+
+	// 模拟重启：新的客户端从同一个文件加载
+	restarted := newExpo(expo.DefaultHost)
+	if err := restarted.InitTokenStore(NewFileTokenStore(path)); err != nil {
+		t.Fatalf("InitTokenStore() error = %v", err)
+	}
+	tokens := restarted.GetTokens()
+	if len(tokens) != 1 || string(tokens[0]) != tokenA {
+		t.Fatalf("重启后 tokens = %v, want [%s]", tokens, tokenA)
+	}
+
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(saved) != 1 || saved[0].DeviceInfo != "iPhone 15" || !saved[0].IsActive {
+		t.Errorf("Load() = %+v, want one active token with device info", saved)
+	}
+}
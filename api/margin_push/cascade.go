@@ -0,0 +1,313 @@
+package margin_push
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/notification"
+	"notice/api/topic"
+)
+
+// defaultCascadeRules 未配置规则时使用的连环清算规则
+var defaultCascadeRules = []config.CascadeRule{
+	{Window: 60, MinValue: 1000000, MinCount: 30},
+	{Window: 300, MinValue: 5000000},
+	{Window: 60, Market: true, MinValue: 5000000, MinCount: 100},
+	{Window: 300, Market: true, MinValue: 20000000},
+}
+
+// dominantShare 一方清算价值占比达到该值时视为主导方向
+const dominantShare = 0.6
+
+// cascadeRule 校验后的连环清算规则
+type cascadeRule struct {
+	key      string
+	window   time.Duration
+	market   bool
+	symbols  map[string]bool // 为空匹配全部
+	minValue float64
+	minCount int
+}
+
+// cascadeStats 窗口内的清算汇总
+type cascadeStats struct {
+	count      int
+	value      float64
+	longValue  float64
+	shortValue float64
+	low, high  float64
+	first      time.Time
+	last       time.Time
+	bySymbol   map[string]*cascadeStats // 全市场规则按交易对细分
+}
+
+// cascadeAlert 一次连环清算告警
+type cascadeAlert struct {
+	rule   *cascadeRule
+	symbol string // 全市场规则为空
+	stats  *cascadeStats
+}
+
+// cascadeDetector 保存最长窗口内的清算订单，按规则检测连环清算
+type cascadeDetector struct {
+	mu        sync.Mutex
+	rules     []*cascadeRule
+	maxWindow time.Duration
+	orders    []liquidationOrder // 按时间升序
+	lastFired map[string]time.Time
+	// notify 发送告警，测试中可替换
+	notify func(a cascadeAlert)
+}
+
+var cascades = newCascadeDetector(defaultCascadeRules)
+
+func newCascadeDetector(rules []config.CascadeRule) *cascadeDetector {
+	d := &cascadeDetector{notify: sendCascadeAlert}
+	d.setRules(rules)
+	return d
+}
+
+// setCascadeRules 设置连环清算规则，disabled 为 true 时关闭检测
+func setCascadeRules(rules []config.CascadeRule, disabled bool) {
+	if disabled {
+		rules = nil
+	} else if len(rules) == 0 {
+		rules = defaultCascadeRules
+	}
+	cascades.setRules(rules)
+}
+
+func (d *cascadeDetector) setRules(rules []config.CascadeRule) {
+	var parsed []*cascadeRule
+	var maxWindow time.Duration
+	for i, r := range rules {
+		if r.Window <= 0 || (r.MinValue <= 0 && r.MinCount <= 0) {
+			log.Printf("忽略无效的连环清算规则 #%d: 需要 Window 以及 MinValue 或 MinCount", i)
+			continue
+		}
+		rule := &cascadeRule{
+			key:      fmt.Sprintf("%d", i),
+			window:   time.Duration(r.Window) * time.Second,
+			market:   r.Market,
+			minValue: r.MinValue,
+			minCount: r.MinCount,
+		}
+		if !r.Market && len(r.Symbols) > 0 {
+			rule.symbols = make(map[string]bool, len(r.Symbols))
+			for _, s := range r.Symbols {
+				rule.symbols[strings.ToUpper(s)] = true
+			}
+		}
+		if rule.window > maxWindow {
+			maxWindow = rule.window
+		}
+		parsed = append(parsed, rule)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules = parsed
+	d.maxWindow = maxWindow
+	d.orders = nil
+	d.lastFired = make(map[string]time.Time)
+}
+
+// add 记录一笔清算订单并检测各规则，返回新触发的告警
+func (d *cascadeDetector) add(o liquidationOrder) []cascadeAlert {
+	d.mu.Lock()
+	if len(d.rules) == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+
+	d.orders = append(d.orders, o)
+	// 保持时间升序，偶尔乱序的订单插入到正确位置
+	for i := len(d.orders) - 1; i > 0 && d.orders[i].time.Before(d.orders[i-1].time); i-- {
+		d.orders[i], d.orders[i-1] = d.orders[i-1], d.orders[i]
+	}
+	latest := d.orders[len(d.orders)-1].time
+	cutoff := latest.Add(-d.maxWindow)
+	drop := 0
+	for drop < len(d.orders) && d.orders[drop].time.Before(cutoff) {
+		drop++
+	}
+	d.orders = d.orders[drop:]
+
+	var alerts []cascadeAlert
+	for _, rule := range d.rules {
+		symbol := ""
+		if !rule.market {
+			if rule.symbols != nil && !rule.symbols[o.symbol] {
+				continue
+			}
+			symbol = o.symbol
+		}
+
+		// 触发后一个窗口内不重复告警，下次告警统计的是全新的清算
+		key := rule.key + "|" + symbol
+		if fired, ok := d.lastFired[key]; ok && latest.Sub(fired) < rule.window {
+			continue
+		}
+
+		stats := d.aggregate(latest.Add(-rule.window), symbol, rule.market)
+		if !rule.triggered(stats) {
+			continue
+		}
+		d.lastFired[key] = latest
+		alerts = append(alerts, cascadeAlert{rule: rule, symbol: symbol, stats: stats})
+	}
+	notify := d.notify
+	d.mu.Unlock()
+
+	for _, a := range alerts {
+		notify(a)
+	}
+	return alerts
+}
+
+// aggregate 汇总 from 之后的清算，symbol 为空时汇总全市场
+func (d *cascadeDetector) aggregate(from time.Time, symbol string, bySymbol bool) *cascadeStats {
+	stats := &cascadeStats{}
+	if bySymbol {
+		stats.bySymbol = make(map[string]*cascadeStats)
+	}
+	for _, o := range d.orders {
+		if o.time.Before(from) || (symbol != "" && o.symbol != symbol) {
+			continue
+		}
+		stats.addOrder(o)
+		if bySymbol {
+			s := stats.bySymbol[o.symbol]
+			if s == nil {
+				s = &cascadeStats{}
+				stats.bySymbol[o.symbol] = s
+			}
+			s.addOrder(o)
+		}
+	}
+	return stats
+}
+
+func (s *cascadeStats) addOrder(o liquidationOrder) {
+	if s.count == 0 || o.price < s.low {
+		s.low = o.price
+	}
+	if o.price > s.high {
+		s.high = o.price
+	}
+	if s.count == 0 || o.time.Before(s.first) {
+		s.first = o.time
+	}
+	if o.time.After(s.last) {
+		s.last = o.time
+	}
+	s.count++
+	s.value += o.value
+	if o.isLong {
+		s.longValue += o.value
+	} else {
+		s.shortValue += o.value
+	}
+}
+
+func (r *cascadeRule) triggered(s *cascadeStats) bool {
+	return (r.minValue > 0 && s.value >= r.minValue) || (r.minCount > 0 && s.count >= r.minCount)
+}
+
+// dominantSide 主导方向及其价值占比
+func (s *cascadeStats) dominantSide() (string, float64) {
+	if s.value <= 0 {
+		return "多空均衡", 0
+	}
+	longShare := s.longValue / s.value
+	switch {
+	case longShare >= dominantShare:
+		return "🟢 多单清算为主", longShare
+	case 1-longShare >= dominantShare:
+		return "🔴 空单清算为主", 1 - longShare
+	}
+	return "多空均衡", math.Max(longShare, 1-longShare)
+}
+
+// priceRange 价格区间及振幅
+func (s *cascadeStats) priceRange() string {
+	low := strconv.FormatFloat(s.low, 'f', -1, 64)
+	high := strconv.FormatFloat(s.high, 'f', -1, 64)
+	if s.low <= 0 {
+		return fmt.Sprintf("%s - %s", low, high)
+	}
+	return fmt.Sprintf("%s - %s (%.2f%%)", low, high, (s.high-s.low)/s.low*100)
+}
+
+// formatWindow 窗口时长，如 1分钟、30秒
+func formatWindow(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d分钟", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d秒", int(d.Seconds()))
+}
+
+// cascadeAlertMessage 连环清算告警的标题、正文和主题
+func cascadeAlertMessage(a cascadeAlert) (string, string, string) {
+	s := a.stats
+	window := formatWindow(a.rule.window)
+	side, share := s.dominantSide()
+
+	var title, scope, t string
+	if a.rule.market {
+		title = fmt.Sprintf("全市场连环清算 %s %s USDT", window, formatToWan(s.value))
+		scope = "全市场"
+		t = topic.Of("liquidation", "market", "cascade")
+	} else {
+		title = fmt.Sprintf("连环清算 %s %s %s USDT", a.symbol, window, formatToWan(s.value))
+		scope = a.symbol
+		t = topic.Of("liquidation", a.symbol, "cascade")
+	}
+
+	message := fmt.Sprintf("🌊 %s %s内清算 %d 笔，价值 %s USDT\n"+
+		"方向: %s (%.1f%%)\n"+
+		"🟢 多单: %s USDT  🔴 空单: %s USDT",
+		scope, window, s.count, formatToWan(s.value),
+		side, share*100,
+		formatToWan(s.longValue), formatToWan(s.shortValue))
+
+	if a.rule.market {
+		symbols := make([]string, 0, len(s.bySymbol))
+		for sym := range s.bySymbol {
+			symbols = append(symbols, sym)
+		}
+		sort.Slice(symbols, func(i, j int) bool { return s.bySymbol[symbols[i]].value > s.bySymbol[symbols[j]].value })
+		if len(symbols) > 3 {
+			symbols = symbols[:3]
+		}
+		message += "\n主要交易对:"
+		for _, sym := range symbols {
+			ss := s.bySymbol[sym]
+			message += fmt.Sprintf("\n%s %d笔 %s USDT 价格 %s", sym, ss.count, formatToWan(ss.value), ss.priceRange())
+		}
+	} else {
+		message += "\n价格区间: " + s.priceRange()
+	}
+	message += fmt.Sprintf("\n时间: %s - %s UTC", s.first.UTC().Format("15:04:05"), s.last.UTC().Format("15:04:05"))
+	return title, message, t
+}
+
+func sendCascadeAlert(a cascadeAlert) {
+	title, message, t := cascadeAlertMessage(a)
+	log.Printf("%s: %s", title, strings.ReplaceAll(message, "\n", " "))
+	go func() {
+		if !notification.Available() {
+			return
+		}
+		if err := notification.SendNotificationWithTitle(message, title, t); err != nil {
+			log.Printf("发送连环清算告警失败: %v", err)
+		}
+	}()
+}
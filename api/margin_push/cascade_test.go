@@ -0,0 +1,118 @@
+package margin_push
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"notice/api/config"
+)
+
+func testOrder(symbol string, isLong bool, price, value float64, at time.Time) liquidationOrder {
+	return liquidationOrder{symbol: symbol, isLong: isLong, price: price, quantity: value / price, value: value, time: at}
+}
+
+func TestCascadePerSymbolWindow(t *testing.T) {
+	d := newCascadeDetector([]config.CascadeRule{{Window: 60, MinValue: 1000000}})
+	var fired []cascadeAlert
+	d.notify = func(a cascadeAlert) { fired = append(fired, a) }
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		d.add(testOrder("BTCUSDT", true, 42000-float64(i)*100, 200000, start.Add(time.Duration(i)*10*time.Second)))
+	}
+	// 其他交易对不计入 BTCUSDT 的窗口
+	d.add(testOrder("ETHUSDT", true, 2200, 500000, start.Add(35*time.Second)))
+	if len(fired) != 0 {
+		t.Fatalf("800k in BTCUSDT should not fire: %+v", fired)
+	}
+
+	// 超出窗口的订单不再计入
+	d.add(testOrder("BTCUSDT", false, 41500, 250000, start.Add(65*time.Second)))
+	if len(fired) != 0 {
+		t.Fatalf("first order has left the window, should not fire: %+v", fired)
+	}
+	d.add(testOrder("BTCUSDT", true, 41400, 200000, start.Add(70*time.Second)))
+	if len(fired) != 1 || fired[0].symbol != "BTCUSDT" || fired[0].stats.count != 5 {
+		t.Fatalf("expected BTCUSDT cascade with 5 orders, got %+v", fired)
+	}
+
+	title, message, topic := cascadeAlertMessage(fired[0])
+	if title != "连环清算 BTCUSDT 1分钟 105.00w USDT" || topic != "liquidation:btcusdt:cascade" {
+		t.Fatalf("unexpected title/topic %q %q", title, topic)
+	}
+	for _, want := range []string{"清算 5 笔", "🟢 多单清算为主 (76.2%)", "价格区间: 41400 - 41900 (1.21%)", "12:00:10 - 12:01:10 UTC"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message missing %q:\n%s", want, message)
+		}
+	}
+
+	// 一个窗口内不重复告警
+	d.add(testOrder("BTCUSDT", true, 41300, 500000, start.Add(90*time.Second)))
+	if len(fired) != 1 {
+		t.Fatalf("should not repeat within the window: %+v", fired)
+	}
+}
+
+func TestCascadeMarketWide(t *testing.T) {
+	d := newCascadeDetector([]config.CascadeRule{
+		{Window: 60, Market: true, MinCount: 4},
+		{Window: 60, Symbols: []string{"SOLUSDT"}, MinCount: 2},
+	})
+	var fired []cascadeAlert
+	d.notify = func(a cascadeAlert) { fired = append(fired, a) }
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.add(testOrder("BTCUSDT", false, 42000, 300000, start))
+	d.add(testOrder("ETHUSDT", false, 2200, 100000, start.Add(time.Second)))
+	d.add(testOrder("BTCUSDT", false, 42100, 300000, start.Add(2*time.Second)))
+	if len(fired) != 0 {
+		t.Fatalf("unexpected alerts %+v", fired)
+	}
+	d.add(testOrder("DOGEUSDT", true, 0.1, 50000, start.Add(3*time.Second)))
+	if len(fired) != 1 || !fired[0].rule.market {
+		t.Fatalf("expected market-wide cascade, got %+v", fired)
+	}
+
+	title, message, topic := cascadeAlertMessage(fired[0])
+	if title != "全市场连环清算 1分钟 75.00w USDT" || topic != "liquidation:market:cascade" {
+		t.Fatalf("unexpected title/topic %q %q", title, topic)
+	}
+	if !strings.Contains(message, "🔴 空单清算为主") || !strings.Contains(message, "BTCUSDT 2笔 60.00w USDT 价格 42000 - 42100") {
+		t.Fatalf("unexpected message:\n%s", message)
+	}
+
+	setCascadeRules(nil, true)
+	defer setCascadeRules(nil, false)
+	if alerts := cascades.add(testOrder("BTCUSDT", true, 42000, 1e9, start)); len(alerts) != 0 {
+		t.Fatal("disabled cascade detection should not alert")
+	}
+}
+
+func TestCascadeDominantSideFromForceOrders(t *testing.T) {
+	d := newCascadeDetector([]config.CascadeRule{{Window: 60, MinCount: 3}})
+	var fired []cascadeAlert
+	d.notify = func(a cascadeAlert) { fired = append(fired, a) }
+
+	// SELL 强平单平掉的是多单
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, price := range []string{"42000", "41900", "41800"} {
+		d.add(parseLiquidation(timedLiquidationEvent("BTCUSDT", "SELL", price, "5", start.Add(time.Duration(i)*time.Second))))
+	}
+	if len(fired) != 1 {
+		t.Fatalf("expected one cascade, got %+v", fired)
+	}
+	if _, message, _ := cascadeAlertMessage(fired[0]); !strings.Contains(message, "🟢 多单清算为主 (100.0%)") {
+		t.Fatalf("SELL liquidations should be long-dominant:\n%s", message)
+	}
+
+	for i, price := range []string{"2200", "2210", "2220"} {
+		d.add(parseLiquidation(timedLiquidationEvent("ETHUSDT", "BUY", price, "50", start.Add(time.Duration(i)*time.Second))))
+	}
+	if len(fired) != 2 {
+		t.Fatalf("expected ETHUSDT cascade, got %+v", fired)
+	}
+	if _, message, _ := cascadeAlertMessage(fired[1]); !strings.Contains(message, "🔴 空单清算为主 (100.0%)") {
+		t.Fatalf("BUY liquidations should be short-dominant:\n%s", message)
+	}
+}
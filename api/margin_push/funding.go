@@ -0,0 +1,436 @@
+package margin_push
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/notification"
+	"notice/api/topic"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// 资金费率/持仓量监控默认参数
+const (
+	defaultHighFundingRate = 0.001
+	defaultLowFundingRate  = -0.001
+	defaultOIChangePct     = 5
+	defaultOIWindow        = 60 * time.Minute
+	defaultOIPollInterval  = 60 * time.Second
+	// 费率回到阈值的该比例以内才解除极值状态，避免在阈值附近反复告警
+	fundingHysteresis = 0.8
+)
+
+// oiPoint 一次持仓量轮询结果
+type oiPoint struct {
+	value float64
+	at    time.Time
+}
+
+// fundingState 单个交易对的最新资金费率和持仓量
+type fundingState struct {
+	symbol      string
+	markPrice   float64
+	fundingRate float64
+	nextFunding time.Time
+	updated     time.Time
+	zone        int // 1 费率过高，-1 费率过低，0 正常
+	oi          []oiPoint
+	// oiAlertAt 上次持仓量告警的时间，告警只与此后的记录比较；oi 保留完整窗口供报告使用
+	oiAlertAt time.Time
+}
+
+// FundingSnapshot 交易对当前的资金费率和持仓量
+type FundingSnapshot struct {
+	Symbol       string    `json:"symbol"`
+	MarkPrice    float64   `json:"mark_price"`
+	FundingRate  float64   `json:"funding_rate"`
+	NextFunding  time.Time `json:"next_funding_time"`
+	OpenInterest float64   `json:"open_interest"`
+	OIValue      float64   `json:"open_interest_value"` // 持仓量 × 标记价格 (USDT)
+	OIChangePct  float64   `json:"open_interest_change_pct"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FundingMonitor 通过标记价格流跟踪资金费率，定时轮询持仓量，超过阈值时推送告警
+type FundingMonitor struct {
+	mu           sync.RWMutex
+	symbols      []string
+	states       map[string]*fundingState
+	highRate     float64
+	lowRate      float64
+	oiChangePct  float64
+	oiWindow     time.Duration
+	pollInterval time.Duration
+	// follow 未配置 Funding.Symbols 时跟随 Watchlist 增删交易对
+	follow bool
+	// changed 交易对变化时通知标记价格流重新订阅
+	changed chan struct{}
+	// notify 发送告警，测试中可替换
+	notify func(title, body, topic string)
+}
+
+var fundingMonitor *FundingMonitor
+
+// StartFundingMonitor 启动资金费率和持仓量监控，cfg.Symbols 为空时使用传入的 Watchlist 交易对，
+// 并通过 AddSymbol/RemoveSymbol 跟随运行时添加和停止的监控
+func StartFundingMonitor(cfg config.FundingConfig, watchSymbols []string) {
+	if cfg.Disabled {
+		log.Printf("资金费率/持仓量监控已关闭")
+		return
+	}
+	symbols := cfg.Symbols
+	if len(symbols) == 0 {
+		symbols = watchSymbols
+	}
+	m := newFundingMonitor(cfg, symbols)
+	if len(m.symbols) == 0 && !m.follow {
+		log.Printf("资金费率/持仓量监控没有可监控的交易对")
+		return
+	}
+	fundingMonitor = m
+
+	log.Printf("开始监控资金费率和持仓量: %v", m.symbols)
+	go m.serveMarkPrice()
+	go m.pollOpenInterest()
+}
+
+// GetFundingMonitor 返回运行中的监控，未启动时返回 nil
+func GetFundingMonitor() *FundingMonitor {
+	return fundingMonitor
+}
+
+func newFundingMonitor(cfg config.FundingConfig, symbols []string) *FundingMonitor {
+	m := &FundingMonitor{
+		states:       make(map[string]*fundingState),
+		highRate:     cfg.HighRate,
+		lowRate:      cfg.LowRate,
+		oiChangePct:  cfg.OIChangePct,
+		oiWindow:     time.Duration(cfg.OIWindow) * time.Minute,
+		pollInterval: time.Duration(cfg.PollInterval) * time.Second,
+		follow:       len(cfg.Symbols) == 0,
+		changed:      make(chan struct{}, 1),
+		notify:       sendFundingAlert,
+	}
+	if m.highRate <= 0 {
+		m.highRate = defaultHighFundingRate
+	}
+	if m.lowRate >= 0 {
+		m.lowRate = defaultLowFundingRate
+	}
+	if m.oiChangePct <= 0 {
+		m.oiChangePct = defaultOIChangePct
+	}
+	if m.oiWindow <= 0 {
+		m.oiWindow = defaultOIWindow
+	}
+	if m.pollInterval <= 0 {
+		m.pollInterval = defaultOIPollInterval
+	}
+
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" || m.states[s] != nil {
+			continue
+		}
+		m.symbols = append(m.symbols, s)
+		m.states[s] = &fundingState{symbol: s}
+	}
+	return m
+}
+
+// AddSymbol 开始监控交易对，已配置 Funding.Symbols 时不跟随 Watchlist，返回是否新增
+func (m *FundingMonitor) AddSymbol(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	m.mu.Lock()
+	if !m.follow || symbol == "" || m.states[symbol] != nil {
+		m.mu.Unlock()
+		return false
+	}
+	m.symbols = append(m.symbols, symbol)
+	m.states[symbol] = &fundingState{symbol: symbol}
+	m.mu.Unlock()
+
+	log.Printf("资金费率/持仓量监控添加交易对: %s", symbol)
+	m.notifyChanged()
+	return true
+}
+
+// RemoveSymbol 停止监控交易对，已配置 Funding.Symbols 时不跟随 Watchlist，返回是否移除
+func (m *FundingMonitor) RemoveSymbol(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	m.mu.Lock()
+	if !m.follow || m.states[symbol] == nil {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.states, symbol)
+	m.symbols = slices.DeleteFunc(m.symbols, func(s string) bool { return s == symbol })
+	m.mu.Unlock()
+
+	log.Printf("资金费率/持仓量监控移除交易对: %s", symbol)
+	m.notifyChanged()
+	return true
+}
+
+func (m *FundingMonitor) notifyChanged() {
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+}
+
+// watchedSymbols 返回当前监控的交易对副本
+func (m *FundingMonitor) watchedSymbols() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.symbols)
+}
+
+// serveMarkPrice 订阅标记价格流，断开后自动重连，交易对变化时重新订阅
+func (m *FundingMonitor) serveMarkPrice() {
+	errHandler := func(err error) {
+		log.Printf("资金费率 WebSocket 错误: %v", err)
+	}
+	for {
+		symbols := m.watchedSymbols()
+		if len(symbols) == 0 {
+			<-m.changed
+			continue
+		}
+		doneC, stopC, err := futures.WsCombinedMarkPriceServe(symbols, m.onMarkPrice, errHandler)
+		if err != nil {
+			log.Printf("订阅标记价格失败: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		select {
+		case <-doneC:
+			log.Printf("标记价格连接断开，5秒后重连")
+			time.Sleep(5 * time.Second)
+		case <-m.changed:
+			close(stopC)
+			<-doneC
+			log.Printf("监控交易对变化，重新订阅标记价格")
+		}
+	}
+}
+
+func (m *FundingMonitor) onMarkPrice(event *futures.WsMarkPriceEvent) {
+	rate, err := strconv.ParseFloat(event.FundingRate, 64)
+	if err != nil {
+		return
+	}
+	price, _ := strconv.ParseFloat(event.MarkPrice, 64)
+	m.updateFunding(event.Symbol, price, rate, time.UnixMilli(event.NextFundingTime), time.UnixMilli(event.Time))
+}
+
+// updateFunding 记录最新资金费率，费率进入极值区间时告警
+func (m *FundingMonitor) updateFunding(symbol string, markPrice, rate float64, nextFunding, at time.Time) {
+	m.mu.Lock()
+	st := m.states[strings.ToUpper(symbol)]
+	if st == nil {
+		m.mu.Unlock()
+		return
+	}
+	st.markPrice = markPrice
+	st.fundingRate = rate
+	st.nextFunding = nextFunding
+	st.updated = at
+
+	zone := st.zone
+	switch {
+	case rate >= m.highRate:
+		zone = 1
+	case rate <= m.lowRate:
+		zone = -1
+	case rate < m.highRate*fundingHysteresis && rate > m.lowRate*fundingHysteresis:
+		zone = 0
+	}
+	entered := zone != 0 && zone != st.zone
+	st.zone = zone
+	m.mu.Unlock()
+
+	if !entered {
+		return
+	}
+
+	title := fmt.Sprintf("资金费率过高 %s", st.symbol)
+	detail := fmt.Sprintf("高于 %s，多头拥挤", formatRate(m.highRate))
+	if zone < 0 {
+		title = fmt.Sprintf("资金费率过低 %s", st.symbol)
+		detail = fmt.Sprintf("低于 %s，空头拥挤", formatRate(m.lowRate))
+	}
+	body := fmt.Sprintf("%s 资金费率 %s %s\n标记价格: %.4f\n下次结算: %s",
+		st.symbol, formatRate(rate), detail, markPrice, nextFunding.UTC().Format("2006-01-02 15:04 UTC"))
+	m.notify(title, body, topic.Of("funding", st.symbol, "rate"))
+}
+
+// pollOpenInterest 定时轮询各交易对的持仓量
+func (m *FundingMonitor) pollOpenInterest() {
+	client := futures.NewClient("", "")
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, symbol := range m.watchedSymbols() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			res, err := client.NewGetOpenInterestService().Symbol(symbol).Do(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("获取 %s 持仓量失败: %v", symbol, err)
+				continue
+			}
+			oi, err := strconv.ParseFloat(res.OpenInterest, 64)
+			if err != nil {
+				continue
+			}
+			m.updateOpenInterest(symbol, oi, time.UnixMilli(res.Time))
+		}
+		<-ticker.C
+	}
+}
+
+// updateOpenInterest 记录持仓量，与窗口内（上次告警之后）最早的记录相比变化超过阈值时告警
+func (m *FundingMonitor) updateOpenInterest(symbol string, oi float64, at time.Time) {
+	m.mu.Lock()
+	st := m.states[strings.ToUpper(symbol)]
+	if st == nil {
+		m.mu.Unlock()
+		return
+	}
+
+	cutoff := at.Add(-m.oiWindow)
+	kept := st.oi[:0]
+	for _, p := range st.oi {
+		if !p.at.Before(cutoff) {
+			kept = append(kept, p)
+		}
+	}
+	st.oi = append(kept, oiPoint{value: oi, at: at})
+
+	base := st.oi[0]
+	for _, p := range st.oi {
+		if !p.at.Before(st.oiAlertAt) {
+			base = p
+			break
+		}
+	}
+	change := 0.0
+	if base.value > 0 {
+		change = (oi - base.value) / base.value * 100
+	}
+	triggered := math.Abs(change) >= m.oiChangePct
+	if triggered {
+		// 以当前持仓量为新的告警基准，避免同一波变化重复告警
+		st.oiAlertAt = at
+	}
+	markPrice := st.markPrice
+	m.mu.Unlock()
+
+	if !triggered {
+		return
+	}
+
+	direction, emoji := "增加", "📈"
+	if change < 0 {
+		direction, emoji = "减少", "📉"
+	}
+	title := fmt.Sprintf("持仓量%s %s", direction, st.symbol)
+	body := fmt.Sprintf("%s %s 持仓量 %s %.2f%%\n%s → %s",
+		emoji, st.symbol, formatDuration(at.Sub(base.at)), change,
+		formatToWan(base.value), formatToWan(oi))
+	if markPrice > 0 {
+		body += fmt.Sprintf("\n持仓价值: %s USDT", formatToWan(oi*markPrice))
+	}
+	m.notify(title, body, topic.Of("funding", st.symbol, "oi"))
+}
+
+// Snapshot 返回各交易对当前的资金费率和持仓量，按配置顺序排列
+func (m *FundingMonitor) Snapshot() []FundingSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]FundingSnapshot, 0, len(m.symbols))
+	for _, s := range m.symbols {
+		st := m.states[s]
+		snap := FundingSnapshot{
+			Symbol:      st.symbol,
+			MarkPrice:   st.markPrice,
+			FundingRate: st.fundingRate,
+			NextFunding: st.nextFunding,
+			UpdatedAt:   st.updated,
+		}
+		if n := len(st.oi); n > 0 {
+			last := st.oi[n-1]
+			snap.OpenInterest = last.value
+			snap.OIValue = last.value * st.markPrice
+			if first := st.oi[0]; first.value > 0 {
+				snap.OIChangePct = (last.value - first.value) / first.value * 100
+			}
+			if last.at.After(snap.UpdatedAt) {
+				snap.UpdatedAt = last.at
+			}
+		}
+		list = append(list, snap)
+	}
+	return list
+}
+
+// fundingReport 统计报告中附带的资金费率/持仓量快照，监控未启动时返回空字符串
+func fundingReport() string {
+	m := GetFundingMonitor()
+	if m == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, s := range m.Snapshot() {
+		if s.UpdatedAt.IsZero() {
+			continue
+		}
+		line := fmt.Sprintf("%s 费率: %s", s.Symbol, formatRate(s.FundingRate))
+		if s.OpenInterest > 0 {
+			line += fmt.Sprintf(" 持仓: %s", formatToWan(s.OpenInterest))
+			if s.OIValue > 0 {
+				line += fmt.Sprintf(" (%s USDT)", formatToWan(s.OIValue))
+			}
+			line += fmt.Sprintf(" %+.2f%%", s.OIChangePct)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n━━━━━━━━━━━━━━━━\n💰 资金费率/持仓量\n" + strings.Join(lines, "\n")
+}
+
+// formatRate 资金费率以百分比显示，如 0.0100%
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.4f%%", rate*100)
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d秒内", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%d分钟内", int(d.Minutes()))
+}
+
+func sendFundingAlert(title, body, t string) {
+	log.Printf("%s: %s", title, strings.ReplaceAll(body, "\n", " "))
+	if !notification.Available() {
+		return
+	}
+	if err := notification.SendNotificationWithTitle(body, title, t); err != nil {
+		log.Printf("发送资金费率/持仓量告警失败: %v", err)
+	}
+}
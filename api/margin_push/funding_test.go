@@ -0,0 +1,142 @@
+package margin_push
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"notice/api/config"
+)
+
+type capturedAlert struct {
+	title, body, topic string
+}
+
+func newTestFundingMonitor(cfg config.FundingConfig, symbols ...string) (*FundingMonitor, *[]capturedAlert) {
+	var alerts []capturedAlert
+	m := newFundingMonitor(cfg, symbols)
+	m.notify = func(title, body, topic string) {
+		alerts = append(alerts, capturedAlert{title, body, topic})
+	}
+	return m, &alerts
+}
+
+func TestFundingRateExtremes(t *testing.T) {
+	m, alerts := newTestFundingMonitor(config.FundingConfig{HighRate: 0.001, LowRate: -0.0005}, "btcusdt", "BTCUSDT")
+	if len(m.symbols) != 1 {
+		t.Fatalf("symbols should be normalized and deduplicated, got %v", m.symbols)
+	}
+
+	now := time.Now()
+	next := now.Add(time.Hour)
+	m.updateFunding("BTCUSDT", 60000, 0.0001, next, now)
+	m.updateFunding("BTCUSDT", 60000, 0.0012, next, now)
+	if len(*alerts) != 1 || (*alerts)[0].topic != "funding:btcusdt:rate" || !strings.Contains((*alerts)[0].title, "过高") {
+		t.Fatalf("expected one high funding alert, got %+v", *alerts)
+	}
+
+	// 回差内不解除，不重复告警
+	m.updateFunding("BTCUSDT", 60000, 0.0009, next, now)
+	m.updateFunding("BTCUSDT", 60000, 0.0011, next, now)
+	if len(*alerts) != 1 {
+		t.Fatalf("should not repeat within hysteresis: %+v", *alerts)
+	}
+
+	// 回到正常区间后再次进入极值才告警
+	m.updateFunding("BTCUSDT", 60000, 0.0002, next, now)
+	m.updateFunding("BTCUSDT", 60000, -0.0006, next, now)
+	if len(*alerts) != 2 || !strings.Contains((*alerts)[1].title, "过低") {
+		t.Fatalf("expected low funding alert, got %+v", *alerts)
+	}
+
+	// 未监控的交易对忽略
+	m.updateFunding("ETHUSDT", 3000, 0.01, next, now)
+	if len(*alerts) != 2 {
+		t.Fatal("unwatched symbols should be ignored")
+	}
+}
+
+func TestOpenInterestChange(t *testing.T) {
+	m, alerts := newTestFundingMonitor(config.FundingConfig{OIChangePct: 5, OIWindow: 60}, "ethusdt")
+	m.updateFunding("ETHUSDT", 2000, 0.0001, time.Time{}, time.Now())
+
+	start := time.Now()
+	m.updateOpenInterest("ETHUSDT", 1000, start)
+	m.updateOpenInterest("ETHUSDT", 1030, start.Add(20*time.Minute))
+	if len(*alerts) != 0 {
+		t.Fatalf("3%% change should not alert: %+v", *alerts)
+	}
+	// 窗口外的记录不参与比较
+	m.updateOpenInterest("ETHUSDT", 1060, start.Add(70*time.Minute))
+	if len(*alerts) != 0 {
+		t.Fatalf("change against expired sample should not alert: %+v", *alerts)
+	}
+	m.updateOpenInterest("ETHUSDT", 1090, start.Add(75*time.Minute))
+	if len(*alerts) != 1 || (*alerts)[0].topic != "funding:ethusdt:oi" || !strings.Contains((*alerts)[0].title, "增加") {
+		t.Fatalf("expected OI increase alert, got %+v", *alerts)
+	}
+
+	// 告警后以当前值为基准
+	m.updateOpenInterest("ETHUSDT", 1100, start.Add(80*time.Minute))
+	if len(*alerts) != 1 {
+		t.Fatalf("should reset baseline after alert: %+v", *alerts)
+	}
+
+	// 告警基准重置不影响报告中的窗口变化（相对 20 分钟时的 1030）
+	snap := m.Snapshot()
+	if len(snap) != 1 || snap[0].OpenInterest != 1100 || snap[0].OIValue != 1100*2000 || math.Abs(snap[0].OIChangePct-6.796) > 0.001 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+}
+
+func TestFundingMonitorFollowsWatchlist(t *testing.T) {
+	m, _ := newTestFundingMonitor(config.FundingConfig{}, "btcusdt")
+	if !m.AddSymbol("ethusdt") || m.AddSymbol("ETHUSDT") {
+		t.Fatal("AddSymbol should add new symbols once")
+	}
+	select {
+	case <-m.changed:
+	default:
+		t.Fatal("AddSymbol should request a resubscribe")
+	}
+	m.updateFunding("ETHUSDT", 3000, 0.0001, time.Time{}, time.Now())
+	if snap := m.Snapshot(); len(snap) != 2 || snap[1].Symbol != "ETHUSDT" || snap[1].MarkPrice != 3000 {
+		t.Fatalf("added symbol should be tracked: %+v", snap)
+	}
+
+	if !m.RemoveSymbol("btcusdt") || m.RemoveSymbol("btcusdt") {
+		t.Fatal("RemoveSymbol should remove watched symbols once")
+	}
+	if got := m.watchedSymbols(); len(got) != 1 || got[0] != "ETHUSDT" {
+		t.Fatalf("unexpected symbols after remove %v", got)
+	}
+
+	// 配置了 Funding.Symbols 时不跟随 Watchlist
+	fixed, _ := newTestFundingMonitor(config.FundingConfig{Symbols: []string{"btcusdt"}}, "btcusdt")
+	if fixed.AddSymbol("ethusdt") || fixed.RemoveSymbol("btcusdt") {
+		t.Fatal("configured symbols should not follow the watchlist")
+	}
+}
+
+func TestFundingReport(t *testing.T) {
+	defer func() { fundingMonitor = nil }()
+
+	fundingMonitor = nil
+	if fundingReport() != "" {
+		t.Fatal("report should be empty without a running monitor")
+	}
+
+	m, _ := newTestFundingMonitor(config.FundingConfig{}, "btcusdt", "ethusdt")
+	fundingMonitor = m
+	m.updateFunding("BTCUSDT", 60000, 0.0001, time.Now().Add(time.Hour), time.Now())
+	m.updateOpenInterest("BTCUSDT", 80000, time.Now())
+
+	report := fundingReport()
+	if !strings.Contains(report, "BTCUSDT 费率: 0.0100% 持仓: 8.00w (48.00亿 USDT)") {
+		t.Fatalf("unexpected report %q", report)
+	}
+	if strings.Contains(report, "ETHUSDT") {
+		t.Fatalf("symbols without data should be skipped: %q", report)
+	}
+}
@@ -0,0 +1,120 @@
+package margin_push
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"notice/api/config"
+	"notice/api/model"
+	"notice/api/notification"
+	"notice/api/topic"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// SetPushConfig 使用配置覆盖默认的清算告警参数和连环清算规则，应在 ForceReceive 之前调用
+func SetPushConfig(cfg config.LiquidationConfig) {
+	pushConfig.EnableLiquidationAlert = !cfg.DisableAlert
+	if cfg.LargeOrderThreshold > 0 {
+		pushConfig.LargeOrderThreshold = cfg.LargeOrderThreshold
+	}
+	if cfg.MaxAlertsPerHour > 0 {
+		pushConfig.MaxAlertsPerHour = cfg.MaxAlertsPerHour
+	}
+	pushConfig.SymbolThresholds = make(map[string]float64, len(cfg.SymbolThresholds))
+	for symbol, threshold := range cfg.SymbolThresholds {
+		if threshold > 0 {
+			pushConfig.SymbolThresholds[strings.ToUpper(symbol)] = threshold
+		}
+	}
+	setCascadeRules(cfg.Cascades, cfg.DisableCascade)
+}
+
+// thresholdFor 交易对的大单告警阈值
+func (c *PushConfig) thresholdFor(symbol string) float64 {
+	if threshold, ok := c.SymbolThresholds[strings.ToUpper(symbol)]; ok {
+		return threshold
+	}
+	return c.LargeOrderThreshold
+}
+
+// liquidationOrder 解析后的单笔清算订单
+type liquidationOrder struct {
+	symbol   string
+	side     string
+	isLong   bool
+	price    float64
+	quantity float64
+	value    float64
+	time     time.Time
+}
+
+// parseLiquidation 解析清算订单，价值按委托价 × 数量计算，与统计口径一致
+func parseLiquidation(event *futures.WsLiquidationOrderEvent) liquidationOrder {
+	order := event.LiquidationOrder
+	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	at := time.UnixMilli(order.TradeTime)
+	if order.TradeTime == 0 {
+		at = time.UnixMilli(event.Time)
+	}
+	return liquidationOrder{
+		symbol:   strings.ToUpper(order.Symbol),
+		side:     string(order.Side),
+		isLong:   model.IsLongLiquidation(string(order.Side)),
+		price:    price,
+		quantity: quantity,
+		value:    price * quantity,
+		time:     at,
+	}
+}
+
+// checkLargeLiquidation 单笔清算价值超过阈值时推送告警，受每小时告警数量限制
+func checkLargeLiquidation(l liquidationOrder) {
+	if !pushConfig.EnableLiquidationAlert {
+		return
+	}
+	if l.value < pushConfig.thresholdFor(l.symbol) {
+		return
+	}
+	if !alertCounter.CanSendAlert() {
+		log.Printf("大额清算告警已达每小时上限(%d)，跳过: %s %s %s USDT",
+			pushConfig.MaxAlertsPerHour, l.symbol, l.side, formatToWan(l.value))
+		return
+	}
+
+	title, message := largeLiquidationMessage(l)
+	go func() {
+		if !notification.Available() {
+			return
+		}
+		if err := notification.SendNotificationWithTitle(message, title, topic.Of("liquidation", l.symbol)); err != nil {
+			log.Printf("发送大额清算告警失败: %v", err)
+		}
+	}()
+}
+
+// largeLiquidationMessage 大额清算告警的标题和正文
+func largeLiquidationMessage(l liquidationOrder) (string, string) {
+	side := "🔴 空单清算"
+	if l.isLong {
+		side = "🟢 多单清算"
+	}
+	title := fmt.Sprintf("大额清算 %s %s USDT", l.symbol, formatToWan(l.value))
+	message := fmt.Sprintf("💥 %s 大额清算\n"+
+		"方向: %s (%s)\n"+
+		"价格: %s\n"+
+		"数量: %s\n"+
+		"价值: %s USDT\n"+
+		"时间: %s",
+		l.symbol,
+		side, l.side,
+		strconv.FormatFloat(l.price, 'f', -1, 64),
+		strconv.FormatFloat(l.quantity, 'f', -1, 64),
+		formatToWan(l.value),
+		l.time.UTC().Format("2006-01-02 15:04:05 UTC"))
+	return title, message
+}
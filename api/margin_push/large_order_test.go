@@ -0,0 +1,68 @@
+package margin_push
+
+import (
+	"strings"
+	"testing"
+
+	"notice/api/config"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func TestSetPushConfigThresholds(t *testing.T) {
+	saved := *pushConfig
+	defer func() { *pushConfig = saved }()
+
+	SetPushConfig(config.LiquidationConfig{
+		LargeOrderThreshold: 50000,
+		SymbolThresholds:    map[string]float64{"btcusdt": 500000, "ETHUSDT": 0},
+		MaxAlertsPerHour:    2,
+	})
+	if !pushConfig.EnableLiquidationAlert || pushConfig.MaxAlertsPerHour != 2 {
+		t.Fatalf("unexpected config %+v", pushConfig)
+	}
+	if got := pushConfig.thresholdFor("BTCUSDT"); got != 500000 {
+		t.Fatalf("BTCUSDT threshold = %v, want 500000", got)
+	}
+	// 非正数阈值忽略，使用默认阈值
+	if got := pushConfig.thresholdFor("ethusdt"); got != 50000 {
+		t.Fatalf("ETHUSDT threshold = %v, want 50000", got)
+	}
+
+	counter := &AlertCounter{hourlyData: make(map[string]int)}
+	if !counter.CanSendAlert() || !counter.CanSendAlert() || counter.CanSendAlert() {
+		t.Fatal("alert counter should allow MaxAlertsPerHour alerts per hour")
+	}
+
+	SetPushConfig(config.LiquidationConfig{DisableAlert: true})
+	if pushConfig.EnableLiquidationAlert {
+		t.Fatal("DisableAlert should turn off liquidation alerts")
+	}
+}
+
+func TestLargeLiquidationMessage(t *testing.T) {
+	event := &futures.WsLiquidationOrderEvent{
+		Time: 1700000000000,
+		LiquidationOrder: futures.WsLiquidationOrder{
+			Symbol:       "BTCUSDT",
+			Side:         "SELL",
+			OrigQuantity: "2.5",
+			Price:        "40000.5",
+			TradeTime:    1700000000000,
+		},
+	}
+	l := parseLiquidation(event)
+	if l.value != 100001.25 || !l.isLong {
+		t.Fatalf("unexpected liquidation %+v", l)
+	}
+
+	title, message := largeLiquidationMessage(l)
+	if title != "大额清算 BTCUSDT 10.00w USDT" {
+		t.Fatalf("unexpected title %q", title)
+	}
+	for _, want := range []string{"多单清算 (SELL)", "价格: 40000.5", "数量: 2.5", "价值: 10.00w USDT", "2023-11-14 22:13:20 UTC"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message missing %q:\n%s", want, message)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package margin_push
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"notice/api/model"
+)
+
+// 清算记录查询的排序字段
+const (
+	sortByTime  = "time"
+	sortByValue = "value"
+)
+
+// 每页条数
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// LiquidationQuery 清算记录查询条件，零值字段不过滤
+type LiquidationQuery struct {
+	Symbol   string
+	Side     string // long / short
+	MinValue float64
+	Start    time.Time
+	End      time.Time
+	SortBy   string // time / value
+	Asc      bool
+	Limit    int
+	Cursor   *LiquidationCursor
+}
+
+// LiquidationCursor 上一页最后一条记录的排序键，排序值相同时按 ID 区分
+type LiquidationCursor struct {
+	SortBy string
+	Time   time.Time
+	Value  float64
+	ID     uint
+}
+
+// LiquidationPage 一页清算记录，NextCursor 为空表示没有更多记录
+type LiquidationPage struct {
+	Data       []model.Liquidation
+	NextCursor string
+}
+
+// ParseLiquidationQuery 解析查询参数: symbol, side(long/short), min_value, start/end(RFC3339),
+// sort(time/value), order(asc/desc), limit, cursor
+func ParseLiquidationQuery(values url.Values) (LiquidationQuery, error) {
+	q := LiquidationQuery{
+		Symbol: strings.ToUpper(strings.TrimSpace(values.Get("symbol"))),
+		SortBy: sortByTime,
+		Limit:  defaultQueryLimit,
+	}
+
+	// 只接受 long/short：强平单方向与被清算仓位相反（SELL 清算多单），不提供 buy/sell 别名以免混淆
+	switch side := strings.ToLower(values.Get("side")); side {
+	case "":
+	case "long", "short":
+		q.Side = side
+	default:
+		return q, fmt.Errorf("invalid side %q, expect long or short", side)
+	}
+
+	if v := values.Get("min_value"); v != "" {
+		minValue, err := strconv.ParseFloat(v, 64)
+		if err != nil || minValue < 0 {
+			return q, fmt.Errorf("invalid min_value %q", v)
+		}
+		q.MinValue = minValue
+	}
+
+	for name, dst := range map[string]*time.Time{"start": &q.Start, "end": &q.End} {
+		if v := values.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return q, fmt.Errorf("invalid %s time, expect RFC3339 like 2006-01-02T15:04:05Z", name)
+			}
+			*dst = t.UTC()
+		}
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.Start.Before(q.End) {
+		return q, fmt.Errorf("start must be before end")
+	}
+
+	switch sort := strings.ToLower(values.Get("sort")); sort {
+	case "", sortByTime:
+	case sortByValue:
+		q.SortBy = sortByValue
+	default:
+		return q, fmt.Errorf("invalid sort %q, expect time or value", sort)
+	}
+
+	switch order := strings.ToLower(values.Get("order")); order {
+	case "", "desc":
+	case "asc":
+		q.Asc = true
+	default:
+		return q, fmt.Errorf("invalid order %q, expect asc or desc", order)
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxQueryLimit {
+			return q, fmt.Errorf("invalid limit %q, expect 1-%d", v, maxQueryLimit)
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil || cursor.SortBy != q.SortBy {
+			return q, fmt.Errorf("invalid cursor")
+		}
+		q.Cursor = cursor
+	}
+	return q, nil
+}
+
+// encodeCursor 以记录的排序键生成下一页游标，格式为 base64(sort:key:id)
+func encodeCursor(sortBy string, l model.Liquidation) string {
+	key := strconv.FormatInt(l.EventTime.UnixMicro(), 10)
+	if sortBy == sortByValue {
+		key = strconv.FormatFloat(l.Value, 'g', -1, 64)
+	}
+	raw := fmt.Sprintf("%s:%s:%d", sortBy, key, l.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*LiquidationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &LiquidationCursor{SortBy: parts[0], ID: uint(id)}
+	switch c.SortBy {
+	case sortByTime:
+		micros, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		c.Time = time.UnixMicro(micros).UTC()
+	case sortByValue:
+		if c.Value, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown cursor sort %q", c.SortBy)
+	}
+	return c, nil
+}
+
+// GetLiquidationStore 返回清算存储，未配置数据库时返回 nil
+func GetLiquidationStore() LiquidationStore {
+	if recorder == nil {
+		return nil
+	}
+	return recorder.store
+}
+
+// QueryLiquidations 按条件分页查询清算记录，多取一条判断是否还有下一页
+func QueryLiquidations(store LiquidationStore, q LiquidationQuery) (*LiquidationPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxQueryLimit {
+		limit = defaultQueryLimit
+	}
+	q.Limit = limit + 1
+
+	records, err := store.QueryLiquidations(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &LiquidationPage{Data: records}
+	if len(records) > limit {
+		page.Data = records[:limit]
+		page.NextCursor = encodeCursor(q.SortBy, page.Data[limit-1])
+	}
+	if page.Data == nil {
+		page.Data = []model.Liquidation{}
+	}
+	return page, nil
+}
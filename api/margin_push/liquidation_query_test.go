@@ -0,0 +1,159 @@
+package margin_push
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"notice/api/model"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestParseLiquidationQuery(t *testing.T) {
+	q, err := ParseLiquidationQuery(url.Values{
+		"symbol": {"btcusdt"}, "side": {"Short"}, "min_value": {"50000"},
+		"start": {"2024-01-01T00:00:00Z"}, "sort": {"value"}, "order": {"asc"}, "limit": {"20"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Symbol != "BTCUSDT" || q.Side != "short" || q.MinValue != 50000 || q.SortBy != sortByValue || !q.Asc || q.Limit != 20 ||
+		!q.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !q.End.IsZero() {
+		t.Fatalf("unexpected query %+v", q)
+	}
+
+	if q, err := ParseLiquidationQuery(url.Values{}); err != nil || q.SortBy != sortByTime || q.Asc || q.Limit != defaultQueryLimit {
+		t.Fatalf("unexpected default query %+v: %v", q, err)
+	}
+
+	timeCursor := encodeCursor(sortByTime, model.Liquidation{Model: gorm.Model{ID: 1}, EventTime: time.Now()})
+	for _, values := range []url.Values{
+		{"side": {"up"}},
+		{"side": {"buy"}},
+		{"side": {"SELL"}},
+		{"min_value": {"-1"}},
+		{"start": {"yesterday"}},
+		{"start": {"2024-01-02T00:00:00Z"}, "end": {"2024-01-01T00:00:00Z"}},
+		{"sort": {"price"}},
+		{"order": {"random"}},
+		{"limit": {"0"}},
+		{"limit": {"1001"}},
+		{"cursor": {"not-a-cursor"}},
+		{"cursor": {timeCursor}, "sort": {"value"}},
+	} {
+		if _, err := ParseLiquidationQuery(values); err == nil {
+			t.Errorf("expected error for %v", values)
+		}
+	}
+}
+
+func TestQueryLiquidationsPagination(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemLiquidationStore()
+	for i, value := range []float64{5000, 80000, 20000, 80000, 150000, 1000} {
+		store.records = append(store.records, model.Liquidation{
+			Model:     gorm.Model{ID: uint(i + 1)},
+			Symbol:    "BTCUSDT",
+			Value:     value,
+			IsLong:    i%2 == 0,
+			EventTime: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	// 按价值降序，价值相同的记录按 ID 降序，翻页不重复不遗漏
+	var ids []uint
+	values := url.Values{"sort": {"value"}, "limit": {"2"}, "min_value": {"2000"}}
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		q, err := ParseLiquidationQuery(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := QueryLiquidations(store, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range page.Data {
+			ids = append(ids, l.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		values.Set("cursor", page.NextCursor)
+	}
+	if want := []uint{5, 4, 2, 3, 1}; !slices.Equal(ids, want) {
+		t.Fatalf("value pages %v, want %v", ids, want)
+	}
+
+	q, _ := ParseLiquidationQuery(url.Values{"side": {"long"}, "order": {"asc"}, "end": {"2024-01-01T12:04:00Z"}})
+	page, err := QueryLiquidations(store, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = nil
+	for _, l := range page.Data {
+		ids = append(ids, l.ID)
+	}
+	if want := []uint{1, 3}; !slices.Equal(ids, want) || page.NextCursor != "" {
+		t.Fatalf("long liquidations %v (cursor %q), want %v", ids, page.NextCursor, want)
+	}
+}
+
+// dryRunQuery 以 DryRun 模式执行 dbLiquidationStore.QueryLiquidations，返回生成的 SQL 和参数
+func dryRunQuery(t *testing.T, q LiquidationQuery) (string, []interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=notice sslmode=disable"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sql string
+	var vars []interface{}
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	})
+	if _, err := NewDBLiquidationStore(db).QueryLiquidations(q); err != nil {
+		t.Fatal(err)
+	}
+	return sql, vars
+}
+
+func TestDBQueryLiquidationsSQL(t *testing.T) {
+	q, err := ParseLiquidationQuery(url.Values{"side": {"long"}, "sort": {"value"}, "min_value": {"1000"}, "limit": {"50"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Cursor = &LiquidationCursor{SortBy: sortByValue, Value: 80000, ID: 4}
+	q.Limit = 51
+
+	sql, vars := dryRunQuery(t, q)
+	for _, want := range []string{"is_long = $1", "value >= $2", "(value, id) < ($3, $4)", "ORDER BY value DESC, id DESC", "LIMIT $5"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL missing %q:\n%s", want, sql)
+		}
+	}
+	if len(vars) != 5 || vars[0] != true || vars[1] != 1000.0 || vars[2] != 80000.0 || vars[3] != uint(4) {
+		t.Fatalf("unexpected vars %v", vars)
+	}
+
+	// 按时间升序翻页，short 过滤为 is_long = false
+	q, _ = ParseLiquidationQuery(url.Values{"side": {"short"}, "order": {"asc"}})
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q.Cursor = &LiquidationCursor{SortBy: sortByTime, Time: at, ID: 7}
+	sql, vars = dryRunQuery(t, q)
+	for _, want := range []string{"is_long = $1", "(event_time, id) > ($2, $3)", "ORDER BY event_time ASC, id ASC"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL missing %q:\n%s", want, sql)
+		}
+	}
+	if len(vars) < 3 || vars[0] != false || vars[1] != at || vars[2] != uint(7) {
+		t.Fatalf("unexpected vars %v", vars)
+	}
+}
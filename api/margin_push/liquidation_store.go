@@ -0,0 +1,419 @@
+package margin_push
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"notice/api/model"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 清算记录批量入库参数
+const (
+	recordBufferSize    = 10000
+	recordBatchSize     = 500
+	recordFlushInterval = 5 * time.Second
+)
+
+// LiquidationStore 清算记录和统计的持久化接口
+type LiquidationStore interface {
+	// SaveLiquidations 批量写入清算记录
+	SaveLiquidations(records []model.Liquidation) error
+	// SaveStats 按 PeriodKey 写入或更新时间段统计
+	SaveStats(stats []model.LiquidationStats) error
+	// LoadStats 加载 since 之后开始的时间段统计
+	LoadStats(since time.Time) ([]model.LiquidationStats, error)
+	// LoadSymbolStats 按交易对和小时汇总 since 之后的清算记录，StartTime 为所在小时
+	LoadSymbolStats(since time.Time) ([]SymbolStats, error)
+	// QueryLiquidations 按条件查询清算记录，从游标之后开始，最多返回 q.Limit 条
+	QueryLiquidations(q LiquidationQuery) ([]model.Liquidation, error)
+}
+
+// dbLiquidationStore 基于 Postgres 的清算存储
+type dbLiquidationStore struct {
+	db *gorm.DB
+}
+
+// NewDBLiquidationStore 创建基于数据库的清算存储
+func NewDBLiquidationStore(db *gorm.DB) LiquidationStore {
+	return &dbLiquidationStore{db: db}
+}
+
+func (s *dbLiquidationStore) SaveLiquidations(records []model.Liquidation) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return s.db.CreateInBatches(&records, recordBatchSize).Error
+}
+
+func (s *dbLiquidationStore) SaveStats(stats []model.LiquidationStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "period_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"period_type", "count", "total_value", "long_count", "short_count",
+			"long_value", "short_value", "start_time", "end_time", "updated_at"}),
+	}).Create(&stats).Error
+}
+
+func (s *dbLiquidationStore) LoadStats(since time.Time) ([]model.LiquidationStats, error) {
+	var stats []model.LiquidationStats
+	if err := s.db.Where("start_time >= ?", since).Order("start_time").Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to load liquidation stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *dbLiquidationStore) LoadSymbolStats(since time.Time) ([]SymbolStats, error) {
+	var stats []SymbolStats
+	err := s.db.Model(&model.Liquidation{}).
+		Select("symbol, date_trunc('hour', event_time AT TIME ZONE 'UTC') AS start_time, "+
+			"count(*) AS count, sum(quantity) AS quantity, sum(value) AS value, "+
+			"sum(CASE WHEN is_long THEN 1 ELSE 0 END) AS long_count, "+
+			"sum(CASE WHEN is_long THEN 0 ELSE 1 END) AS short_count, "+
+			"sum(CASE WHEN is_long THEN value ELSE 0 END) AS long_value, "+
+			"sum(CASE WHEN is_long THEN 0 ELSE value END) AS short_value").
+		Where("event_time >= ?", since).
+		Group("symbol, start_time").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load liquidation symbol stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *dbLiquidationStore) QueryLiquidations(q LiquidationQuery) ([]model.Liquidation, error) {
+	tx := s.db.Model(&model.Liquidation{})
+	if q.Symbol != "" {
+		tx = tx.Where("symbol = ?", q.Symbol)
+	}
+	if q.Side != "" {
+		tx = tx.Where("is_long = ?", q.Side == "long")
+	}
+	if q.MinValue > 0 {
+		tx = tx.Where("value >= ?", q.MinValue)
+	}
+	if !q.Start.IsZero() {
+		tx = tx.Where("event_time >= ?", q.Start)
+	}
+	if !q.End.IsZero() {
+		tx = tx.Where("event_time < ?", q.End)
+	}
+
+	column, op, dir := "event_time", "<", "DESC"
+	if q.SortBy == sortByValue {
+		column = "value"
+	}
+	if q.Asc {
+		op, dir = ">", "ASC"
+	}
+	if c := q.Cursor; c != nil {
+		var key interface{} = c.Time
+		if c.SortBy == sortByValue {
+			key = c.Value
+		}
+		tx = tx.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, op), key, c.ID)
+	}
+
+	var records []model.Liquidation
+	err := tx.Order(fmt.Sprintf("%s %s, id %s", column, dir, dir)).Limit(q.Limit).Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query liquidations: %w", err)
+	}
+	return records, nil
+}
+
+// liquidationRecorder 缓冲清算记录，按批量或定时写入存储，并同步更新时间段统计
+type liquidationRecorder struct {
+	store   LiquidationStore
+	stats   *Stats
+	events  chan model.Liquidation
+	dropped atomic.Int64
+	// saveStats 统计恢复成功后才写入统计：写入是覆盖，内存中不完整的统计会覆盖数据库中正确的记录
+	saveStats bool
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+var recorder *liquidationRecorder
+
+// InitLiquidationStore 从存储恢复清算统计并开始记录清算，应在 ForceReceive 之前调用。
+// 恢复失败时只写入清算记录，不写入统计
+func InitLiquidationStore(store LiquidationStore) error {
+	r, err := startLiquidationRecorder(store, globalStats, time.Now().UTC())
+	recorder = r
+	go r.run()
+	return err
+}
+
+// StopLiquidationStore 停止记录清算，写入队列中剩余的记录，应在关闭数据库之前调用
+func StopLiquidationStore() {
+	if recorder != nil {
+		recorder.Stop()
+	}
+}
+
+// startLiquidationRecorder 恢复统计并创建记录器，恢复失败时记录器不写入统计
+func startLiquidationRecorder(store LiquidationStore, stats *Stats, now time.Time) (*liquidationRecorder, error) {
+	r := newLiquidationRecorder(store, stats)
+	if err := restoreStats(stats, store, now); err != nil {
+		r.saveStats = false
+		log.Printf("清算统计恢复失败，本次运行不写入统计: %v", err)
+		return r, err
+	}
+	return r, nil
+}
+
+func newLiquidationRecorder(store LiquidationStore, stats *Stats) *liquidationRecorder {
+	return &liquidationRecorder{
+		store:     store,
+		stats:     stats,
+		events:    make(chan model.Liquidation, recordBufferSize),
+		saveStats: true,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// recordLiquidation 将清算事件加入入库队列，未配置存储时忽略
+func recordLiquidation(event *futures.WsLiquidationOrderEvent) {
+	if recorder == nil {
+		return
+	}
+	recorder.record(liquidationRecord(event))
+}
+
+// liquidationRecord 将清算事件转换为数据库记录，Value/IsLong 由 BeforeCreate 计算
+func liquidationRecord(event *futures.WsLiquidationOrderEvent) model.Liquidation {
+	o := event.LiquidationOrder
+	quantity, _ := strconv.ParseFloat(o.OrigQuantity, 64)
+	price, _ := strconv.ParseFloat(o.Price, 64)
+
+	eventTime := time.Now().UTC()
+	if event.Time > 0 {
+		eventTime = time.UnixMilli(event.Time).UTC()
+	}
+	exchangeTime := eventTime
+	if o.TradeTime > 0 {
+		exchangeTime = time.UnixMilli(o.TradeTime).UTC()
+	}
+	return model.Liquidation{
+		Symbol:       strings.ToUpper(o.Symbol),
+		Side:         string(o.Side),
+		OrderType:    string(o.OrderType),
+		TimeInForce:  string(o.TimeInForce),
+		Quantity:     quantity,
+		Price:        price,
+		EventTime:    eventTime,
+		ExchangeTime: exchangeTime,
+	}
+}
+
+// record 非阻塞入队，队列已满时丢弃，避免数据库变慢拖住 WebSocket 处理
+func (r *liquidationRecorder) record(l model.Liquidation) {
+	select {
+	case r.events <- l:
+	default:
+		if n := r.dropped.Add(1); n%1000 == 1 {
+			log.Printf("清算入库队列已满，已丢弃 %d 条记录", n)
+		}
+	}
+}
+
+func (r *liquidationRecorder) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(recordFlushInterval)
+	defer ticker.Stop()
+
+	var batch []model.Liquidation
+	for {
+		select {
+		case l := <-r.events:
+			batch = append(batch, l)
+			if len(batch) < recordBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-r.stop:
+			r.drain(batch)
+			return
+		}
+		r.flush(batch)
+		batch = nil
+	}
+}
+
+// drain 写入当前批次和队列中剩余的记录
+func (r *liquidationRecorder) drain(batch []model.Liquidation) {
+	for {
+		select {
+		case l := <-r.events:
+			batch = append(batch, l)
+			if len(batch) >= recordBatchSize {
+				r.flush(batch)
+				batch = nil
+			}
+		default:
+			if len(batch) > 0 {
+				r.flush(batch)
+			}
+			log.Printf("清算入库已停止")
+			return
+		}
+	}
+}
+
+// Stop 停止后台写入并等待剩余记录写完
+func (r *liquidationRecorder) Stop() {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	<-r.done
+}
+
+// flush 写入一批清算记录，并更新这些记录所在小时和日的统计
+func (r *liquidationRecorder) flush(batch []model.Liquidation) {
+	if err := r.store.SaveLiquidations(batch); err != nil {
+		log.Printf("保存 %d 条清算记录失败: %v", len(batch), err)
+	}
+
+	var hourKeys, dayKeys []string
+	seen := make(map[string]bool)
+	for _, l := range batch {
+		hourKey := l.EventTime.UTC().Format("2006-01-02-15")
+		dayKey := l.EventTime.UTC().Format("2006-01-02")
+		if !seen[hourKey] {
+			seen[hourKey] = true
+			hourKeys = append(hourKeys, hourKey)
+		}
+		if !seen[dayKey] {
+			seen[dayKey] = true
+			dayKeys = append(dayKeys, dayKey)
+		}
+	}
+	if !r.saveStats {
+		return
+	}
+	// 写入内存中的完整统计，重复写入同一时间段是幂等的
+	if err := r.store.SaveStats(r.stats.periodRecords(hourKeys, dayKeys)); err != nil {
+		log.Printf("保存清算统计失败: %v", err)
+	}
+}
+
+// periodRecords 将指定时间段的统计转换为数据库记录
+func (s *Stats) periodRecords(hourKeys, dayKeys []string) []model.LiquidationStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []model.LiquidationStats
+	convert := func(periodType, key string, stats *PeriodStats, length time.Duration) {
+		if stats == nil {
+			return
+		}
+		records = append(records, model.LiquidationStats{
+			PeriodType: periodType,
+			PeriodKey:  key,
+			Count:      stats.Count,
+			TotalValue: stats.Value,
+			LongCount:  stats.LongCount,
+			ShortCount: stats.ShortCount,
+			LongValue:  stats.LongValue,
+			ShortValue: stats.ShortValue,
+			StartTime:  stats.StartTime,
+			EndTime:    stats.StartTime.Add(length),
+		})
+	}
+	for _, key := range hourKeys {
+		convert("hourly", key, s.hourlyStats[key], time.Hour)
+	}
+	for _, key := range dayKeys {
+		convert("daily", key, s.dailyStats[key], 24*time.Hour)
+	}
+	return records
+}
+
+// restoreStats 从存储恢复最近48小时的小时统计和最近7天的日统计。
+// 总量取自 liquidation_stats，按交易对的细分由 liquidations 汇总；缺少统计行的时间段用交易对汇总补齐
+func restoreStats(s *Stats, store LiquidationStore, now time.Time) error {
+	hourCutoff := now.Add(-maxStatsHours * time.Hour)
+	dayCutoff := now.Add(-7 * 24 * time.Hour).Truncate(24 * time.Hour)
+
+	periods, err := store.LoadStats(dayCutoff)
+	if err != nil {
+		return err
+	}
+	symbols, err := store.LoadSymbolStats(dayCutoff)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range symbols {
+		start := row.StartTime.UTC()
+		stats := row.PeriodStats
+		if !start.Before(hourCutoff) {
+			stats.StartTime = start
+			symbolStats(s.hourlySymbolStats, start.Format("2006-01-02-15"), row.Symbol, start).merge(&stats)
+		}
+		day := start.Truncate(24 * time.Hour)
+		symbolStats(s.dailySymbolStats, day.Format("2006-01-02"), row.Symbol, day).merge(&stats)
+	}
+
+	for _, row := range periods {
+		start := row.StartTime.UTC()
+		stats := &PeriodStats{
+			Count:      row.Count,
+			Value:      row.TotalValue,
+			LongCount:  row.LongCount,
+			ShortCount: row.ShortCount,
+			LongValue:  row.LongValue,
+			ShortValue: row.ShortValue,
+			StartTime:  start,
+		}
+		switch row.PeriodType {
+		case "hourly":
+			if start.Before(hourCutoff) {
+				continue
+			}
+			s.hourlyStats[row.PeriodKey] = stats
+		case "daily":
+			s.dailyStats[row.PeriodKey] = stats
+		}
+	}
+
+	// 统计表不记录数量，由交易对汇总补齐；缺少统计行的时间段整体由交易对汇总得出
+	fill := func(totals map[string]*PeriodStats, buckets map[string]map[string]*PeriodStats) {
+		for key, bucket := range buckets {
+			sum := &PeriodStats{}
+			for _, stats := range bucket {
+				sum.merge(stats)
+				sum.StartTime = stats.StartTime
+			}
+			if totals[key] == nil {
+				totals[key] = sum
+			} else {
+				totals[key].Quantity = sum.Quantity
+			}
+		}
+	}
+	fill(s.hourlyStats, s.hourlySymbolStats)
+	fill(s.dailyStats, s.dailySymbolStats)
+
+	log.Printf("已从数据库恢复清算统计: %d 个小时, %d 天", len(s.hourlyStats), len(s.dailyStats))
+	return nil
+}
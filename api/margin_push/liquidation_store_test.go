@@ -0,0 +1,229 @@
+package margin_push
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"notice/api/model"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"gorm.io/gorm"
+)
+
+// memLiquidationStore 内存实现，LoadSymbolStats 模拟数据库中的按小时汇总
+type memLiquidationStore struct {
+	records []model.Liquidation
+	stats   map[string]model.LiquidationStats
+}
+
+func newMemLiquidationStore() *memLiquidationStore {
+	return &memLiquidationStore{stats: make(map[string]model.LiquidationStats)}
+}
+
+func (m *memLiquidationStore) SaveLiquidations(records []model.Liquidation) error {
+	m.records = append(m.records, records...)
+	return nil
+}
+
+func (m *memLiquidationStore) SaveStats(stats []model.LiquidationStats) error {
+	for _, s := range stats {
+		m.stats[s.PeriodKey] = s
+	}
+	return nil
+}
+
+func (m *memLiquidationStore) LoadStats(since time.Time) ([]model.LiquidationStats, error) {
+	var list []model.LiquidationStats
+	for _, s := range m.stats {
+		if !s.StartTime.Before(since) {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (m *memLiquidationStore) LoadSymbolStats(since time.Time) ([]SymbolStats, error) {
+	buckets := make(map[string]*SymbolStats)
+	var list []SymbolStats
+	for _, l := range m.records {
+		if l.EventTime.Before(since) {
+			continue
+		}
+		hour := l.EventTime.Truncate(time.Hour)
+		key := l.Symbol + hour.String()
+		if buckets[key] == nil {
+			buckets[key] = &SymbolStats{Symbol: l.Symbol, PeriodStats: PeriodStats{StartTime: hour}}
+		}
+		buckets[key].add(l.Quantity, l.Quantity*l.Price, model.IsLongLiquidation(l.Side))
+	}
+	for _, s := range buckets {
+		list = append(list, *s)
+	}
+	return list, nil
+}
+
+func (m *memLiquidationStore) QueryLiquidations(q LiquidationQuery) ([]model.Liquidation, error) {
+	key := func(l model.Liquidation) float64 {
+		if q.SortBy == sortByValue {
+			return l.Value
+		}
+		return float64(l.EventTime.UnixMicro())
+	}
+	// after 判断 a 是否排在 b 之后
+	after := func(a, b model.Liquidation) bool {
+		ka, kb := key(a), key(b)
+		if ka == kb {
+			return a.ID != b.ID && (a.ID > b.ID) == q.Asc
+		}
+		return (ka > kb) == q.Asc
+	}
+
+	var list []model.Liquidation
+	for _, l := range m.records {
+		if (q.Symbol != "" && l.Symbol != q.Symbol) || (q.Side != "" && l.IsLong != (q.Side == "long")) ||
+			l.Value < q.MinValue || (!q.Start.IsZero() && l.EventTime.Before(q.Start)) ||
+			(!q.End.IsZero() && !l.EventTime.Before(q.End)) {
+			continue
+		}
+		if c := q.Cursor; c != nil && !after(l, model.Liquidation{Model: gorm.Model{ID: c.ID}, EventTime: c.Time, Value: c.Value}) {
+			continue
+		}
+		list = append(list, l)
+	}
+	sort.Slice(list, func(i, j int) bool { return after(list[j], list[i]) })
+	if len(list) > q.Limit {
+		list = list[:q.Limit]
+	}
+	return list, nil
+}
+
+func timedLiquidationEvent(symbol string, side futures.SideType, price, quantity string, at time.Time) *futures.WsLiquidationOrderEvent {
+	event := liquidationEvent(symbol, side, price, quantity)
+	event.Time = at.UnixMilli()
+	return event
+}
+
+func TestLiquidationStoreRoundTrip(t *testing.T) {
+	now := time.Now().UTC()
+	store := newMemLiquidationStore()
+	live := NewStats()
+	r := newLiquidationRecorder(store, live)
+
+	events := []*futures.WsLiquidationOrderEvent{
+		timedLiquidationEvent("BTCUSDT", "BUY", "40000", "1", now.Add(-3*time.Hour)),
+		timedLiquidationEvent("ETHUSDT", "SELL", "2000", "5", now.Add(-time.Hour)),
+		timedLiquidationEvent("btcusdt", "SELL", "40000", "0.5", now),
+	}
+	var batch []model.Liquidation
+	for _, e := range events {
+		live.AddLiquidation(e)
+		batch = append(batch, liquidationRecord(e))
+	}
+	r.flush(batch)
+
+	if len(store.records) != 3 || store.records[2].Symbol != "BTCUSDT" || !store.records[2].EventTime.Equal(now.Truncate(time.Millisecond)) {
+		t.Fatalf("unexpected records %+v", store.records)
+	}
+	hour := store.stats[now.Format("2006-01-02-15")]
+	if hour.PeriodType != "hourly" || hour.Count != 1 || hour.LongValue != 20000 || !hour.EndTime.Equal(hour.StartTime.Add(time.Hour)) {
+		t.Fatalf("unexpected hourly stats %+v", hour)
+	}
+	if day := store.stats[now.Format("2006-01-02")]; day.PeriodType != "daily" || day.Count == 0 {
+		t.Fatalf("unexpected daily stats %+v", day)
+	}
+
+	restored := NewStats()
+	if err := restoreStats(restored, store, now); err != nil {
+		t.Fatal(err)
+	}
+	wantCount, wantQty, wantValue, _, _, wantLong, _, _ := live.GetPeriodStats(24)
+	count, qty, value, _, _, long, _, _ := restored.GetPeriodStats(24)
+	if count != wantCount || qty != wantQty || value != wantValue || long != wantLong {
+		t.Fatalf("restored 24h stats %d/%v/%v/%v, want %d/%v/%v/%v", count, qty, value, long, wantCount, wantQty, wantValue, wantLong)
+	}
+	top := restored.TopSymbols(4, 0)
+	if len(top) != 2 || top[0].Symbol != "BTCUSDT" || top[0].Count != 2 || top[0].Value != 60000 {
+		t.Fatalf("unexpected restored leaderboard %+v", top)
+	}
+}
+
+func TestRestoreStatsRetention(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 30, 0, 0, time.UTC)
+	store := newMemLiquidationStore()
+	old := now.Add(-72 * time.Hour).Truncate(time.Hour)
+	store.stats[old.Format("2006-01-02-15")] = model.LiquidationStats{
+		PeriodType: "hourly", PeriodKey: old.Format("2006-01-02-15"), Count: 1, TotalValue: 100, StartTime: old,
+	}
+	// 没有统计行的小时由清算记录补齐
+	store.records = []model.Liquidation{
+		{Symbol: "ETHUSDT", Side: "SELL", Quantity: 2, Price: 2000, EventTime: now.Add(-2 * time.Hour)},
+	}
+
+	s := NewStats()
+	if err := restoreStats(s, store, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.hourlyStats[old.Format("2006-01-02-15")]; ok {
+		t.Fatal("hourly stats older than 48h should not be restored")
+	}
+	filled := s.hourlyStats[now.Add(-2*time.Hour).Format("2006-01-02-15")]
+	if filled == nil || filled.Count != 1 || filled.Quantity != 2 || filled.LongValue != 4000 {
+		t.Fatalf("unexpected filled hour %+v", filled)
+	}
+	if day := s.dailyStats["2024-01-10"]; day == nil || day.Value != 4000 || !day.StartTime.Equal(now.Truncate(24*time.Hour)) {
+		t.Fatalf("unexpected filled day %+v", day)
+	}
+}
+
+// failingStatsStore LoadStats 失败的存储
+type failingStatsStore struct {
+	*memLiquidationStore
+}
+
+func (f failingStatsStore) LoadStats(since time.Time) ([]model.LiquidationStats, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRecorderSkipsStatsAfterRestoreFailure(t *testing.T) {
+	now := time.Now().UTC()
+	mem := newMemLiquidationStore()
+	hourKey := now.Format("2006-01-02-15")
+	saved := model.LiquidationStats{PeriodType: "hourly", PeriodKey: hourKey, Count: 120, TotalValue: 3000000, StartTime: now.Truncate(time.Hour)}
+	mem.stats[hourKey] = saved
+
+	live := NewStats()
+	r, err := startLiquidationRecorder(failingStatsStore{mem}, live, now)
+	if err == nil {
+		t.Fatal("expected restore error")
+	}
+
+	event := timedLiquidationEvent("BTCUSDT", "SELL", "40000", "1", now)
+	live.AddLiquidation(event)
+	r.flush([]model.Liquidation{liquidationRecord(event)})
+
+	if len(mem.records) != 1 {
+		t.Fatalf("liquidations should still be saved, got %d", len(mem.records))
+	}
+	if got := mem.stats[hourKey]; got != saved || len(mem.stats) != 1 {
+		t.Fatalf("stats should not be overwritten after a failed restore: %+v", mem.stats)
+	}
+}
+
+func TestLiquidationRecorderStopFlushesBuffer(t *testing.T) {
+	store := newMemLiquidationStore()
+	r := newLiquidationRecorder(store, NewStats())
+	go r.run()
+
+	now := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		r.record(liquidationRecord(timedLiquidationEvent("ETHUSDT", "BUY", "2000", "1", now)))
+	}
+	r.Stop()
+	r.Stop()
+
+	if len(store.records) != 3 {
+		t.Fatalf("Stop should flush buffered records, got %d", len(store.records))
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"notice/api/expo"
+	"notice/api/model"
 	"notice/api/notification"
 
 	"github.com/adshao/go-binance/v2/futures"
@@ -18,6 +19,9 @@ type Stats struct {
 	// 按时间段统计的清算数据
 	hourlyStats map[string]*PeriodStats // key: "2024-01-01-15" (年-月-日-时)
 	dailyStats  map[string]*PeriodStats // key: "2024-01-01" (年-月-日)
+	// 按交易对细分的统计，key 同上，内层 key 为交易对
+	hourlySymbolStats map[string]map[string]*PeriodStats
+	dailySymbolStats  map[string]map[string]*PeriodStats
 	// 程序启动时间
 	startTime time.Time
 }
@@ -33,11 +37,54 @@ type PeriodStats struct {
 	StartTime  time.Time `json:"start_time"`
 }
 
+// add 累加一笔清算
+func (p *PeriodStats) add(quantity, value float64, isLong bool) {
+	p.Count++
+	p.Quantity += quantity
+	p.Value += value
+	if isLong {
+		p.LongCount++
+		p.LongValue += value
+	} else {
+		p.ShortCount++
+		p.ShortValue += value
+	}
+}
+
+// merge 累加另一个时间段的统计
+func (p *PeriodStats) merge(o *PeriodStats) {
+	p.Count += o.Count
+	p.Quantity += o.Quantity
+	p.Value += o.Value
+	p.LongCount += o.LongCount
+	p.ShortCount += o.ShortCount
+	p.LongValue += o.LongValue
+	p.ShortValue += o.ShortValue
+}
+
 func (s *Stats) AddLiquidation(event *futures.WsLiquidationOrderEvent) {
+	// 解析数量和价值
+	quantity, _ := strconv.ParseFloat(event.LiquidationOrder.OrigQuantity, 64)
+	price, _ := strconv.ParseFloat(event.LiquidationOrder.Price, 64)
+	value := quantity * price
+
+	// 判断多单还是空单
+	isLong := model.IsLongLiquidation(string(event.LiquidationOrder.Side))
+
+	// 按事件时间归入时间段，与入库的清算记录一致
+	at := time.Now().UTC()
+	if event.Time > 0 {
+		at = time.UnixMilli(event.Time).UTC()
+	}
+	s.add(strings.ToUpper(event.LiquidationOrder.Symbol), quantity, value, isLong, at)
+}
+
+// add 将一笔清算计入 at 所在的小时和日统计
+func (s *Stats) add(symbol string, quantity, value float64, isLong bool, at time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now().UTC()
+	now := at.UTC()
 
 	// 生成时间键
 	hourKey := now.Format("2006-01-02-15") // 年-月-日-时
@@ -50,6 +97,12 @@ func (s *Stats) AddLiquidation(event *futures.WsLiquidationOrderEvent) {
 	if s.dailyStats == nil {
 		s.dailyStats = make(map[string]*PeriodStats)
 	}
+	if s.hourlySymbolStats == nil {
+		s.hourlySymbolStats = make(map[string]map[string]*PeriodStats)
+	}
+	if s.dailySymbolStats == nil {
+		s.dailySymbolStats = make(map[string]map[string]*PeriodStats)
+	}
 
 	// 初始化小时统计
 	if s.hourlyStats[hourKey] == nil {
@@ -65,35 +118,13 @@ func (s *Stats) AddLiquidation(event *futures.WsLiquidationOrderEvent) {
 		}
 	}
 
-	// 解析数量和价值
-	quantity, _ := strconv.ParseFloat(event.LiquidationOrder.OrigQuantity, 64)
-	price, _ := strconv.ParseFloat(event.LiquidationOrder.Price, 64)
-	value := quantity * price
-
-	// 判断多单还是空单
-	isLong := event.LiquidationOrder.Side == "BUY"
-
 	// 更新统计数据
-	s.hourlyStats[hourKey].Count++
-	s.hourlyStats[hourKey].Quantity += quantity
-	s.hourlyStats[hourKey].Value += value
+	s.hourlyStats[hourKey].add(quantity, value, isLong)
+	s.dailyStats[dayKey].add(quantity, value, isLong)
 
-	s.dailyStats[dayKey].Count++
-	s.dailyStats[dayKey].Quantity += quantity
-	s.dailyStats[dayKey].Value += value
-
-	// 更新多空统计
-	if isLong {
-		s.hourlyStats[hourKey].LongCount++
-		s.hourlyStats[hourKey].LongValue += value
-		s.dailyStats[dayKey].LongCount++
-		s.dailyStats[dayKey].LongValue += value
-	} else {
-		s.hourlyStats[hourKey].ShortCount++
-		s.hourlyStats[hourKey].ShortValue += value
-		s.dailyStats[dayKey].ShortCount++
-		s.dailyStats[dayKey].ShortValue += value
-	}
+	// 按交易对统计
+	symbolStats(s.hourlySymbolStats, hourKey, symbol, now.Truncate(time.Hour)).add(quantity, value, isLong)
+	symbolStats(s.dailySymbolStats, dayKey, symbol, now.Truncate(24*time.Hour)).add(quantity, value, isLong)
 }
 
 // 获取当前小时统计
@@ -164,6 +195,7 @@ func (s *Stats) CleanOldHourlyData() {
 	for key, stats := range s.hourlyStats {
 		if stats.StartTime.Before(cutoffTime) {
 			delete(s.hourlyStats, key)
+			delete(s.hourlySymbolStats, key)
 		}
 	}
 }
@@ -179,6 +211,7 @@ func (s *Stats) CleanOldDailyData() {
 	for key, stats := range s.dailyStats {
 		if stats.StartTime.Before(cutoffTime) {
 			delete(s.dailyStats, key)
+			delete(s.dailySymbolStats, key)
 		}
 	}
 }
@@ -188,6 +221,9 @@ func NewStats() *Stats {
 		startTime:   time.Now().UTC(),
 		hourlyStats: make(map[string]*PeriodStats),
 		dailyStats:  make(map[string]*PeriodStats),
+
+		hourlySymbolStats: make(map[string]map[string]*PeriodStats),
+		dailySymbolStats:  make(map[string]map[string]*PeriodStats),
 	}
 }
 
@@ -222,11 +258,10 @@ func sendStartupNotification() {
 
 	// 发送启动通知推送
 	go func() {
-		// 等待一小段时间确保expo客户端已初始化
+		// 等待一小段时间确保通知渠道已初始化
 		time.Sleep(2 * time.Second)
 		
-		client := expo.GetExpoClient()
-		if client != nil && client.GetTokenCount() > 0 {
+		if notification.Available() {
 			err := notification.SendNotificationWithTitle(message, "清算监控系统", "liquidation")
 			if err != nil {
 				log.Printf("发送启动通知推送失败: %v", err)
@@ -234,17 +269,18 @@ func sendStartupNotification() {
 				log.Printf("启动通知推送发送成功")
 			}
 		} else {
-			log.Printf("跳过启动通知推送: 没有注册的推送token")
+			log.Printf("跳过启动通知推送: 没有可用的通知渠道")
 		}
 	}()
 }
 
 // 配置参数
 type PushConfig struct {
-	EnableLiquidationAlert bool    // 是否启用清算告警
-	LargeOrderThreshold    float64 // 大单告警阈值(USDT)
-	EnableStatsReport      bool    // 是否启用统计报告
-	MaxAlertsPerHour       int     // 每小时最大告警数量
+	EnableLiquidationAlert bool               // 是否启用清算告警
+	LargeOrderThreshold    float64            // 大单告警阈值(USDT)
+	SymbolThresholds       map[string]float64 // 按交易对的大单告警阈值(USDT)，未配置的交易对使用 LargeOrderThreshold
+	EnableStatsReport      bool               // 是否启用统计报告
+	MaxAlertsPerHour       int                // 每小时最大告警数量
 }
 
 var pushConfig = &PushConfig{
@@ -297,52 +333,46 @@ func (ac *AlertCounter) CleanOldData() {
 func logStats(period string) {
 	now := time.Now().UTC()
 
+	// 定时器在整点触发，统计和排行都只包含已结束的小时
 	switch period {
 	case "1小时":
 		// 显示刚刚结束的小时统计
-		count, quantity, value, longCount, shortCount, longValue, shortValue, timeKey := globalStats.GetCurrentHourStats()
+		total, top, keys := globalStats.completedHours(now, 1, reportTopN)
+		timeKey := keys[0]
 		log.Printf("[%s统计] UTC时间: %s, 清算订单数: %d, 总数量: %.4f, 总价值: %.4f",
-			period, timeKey, count, quantity, value)
+			period, timeKey, total.Count, total.Quantity, total.Value)
 		log.Printf("   多单: %d笔 价值: %s USDT, 空单: %d笔 价值: %s USDT",
-			longCount, formatToWan(longValue), shortCount, formatToWan(shortValue))
+			total.LongCount, formatToWan(total.LongValue), total.ShortCount, formatToWan(total.ShortValue))
 
 		// 发送1小时统计推送通知
-		sendStatsReport(period, count, quantity, value, longCount, shortCount, longValue, shortValue, timeKey)
+		sendStatsReport(period, total.Count, total.Quantity, total.Value, total.LongCount, total.ShortCount, total.LongValue, total.ShortValue, timeKey, top)
 
-	case "4小时":
-		// 显示过去4小时的总统计（UTC固定时间点：0,4,8,12,16,20点）
-		count, quantity, value, longCount, shortCount, longValue, shortValue, periods := globalStats.GetPeriodStats(4)
-		log.Printf("[%s统计] UTC时间: %s, 过去4小时清算订单数: %d, 总数量: %.4f, 总价值: %.4f",
-			period, now.Format("2006-01-02 15:04"), count, quantity, value)
-		log.Printf("   多单: %d笔 价值: %s USDT, 空单: %d笔 价值: %s USDT",
-			longCount, formatToWan(longValue), shortCount, formatToWan(shortValue))
-		log.Printf("   包含时段: %v", periods)
-
-		// 发送4小时统计推送通知
-		sendStatsReport(period, count, quantity, value, longCount, shortCount, longValue, shortValue, now.Format("2006-01-02 15:04"))
-
-	case "8小时":
-		// 显示过去8小时的总统计（UTC固定时间点：0,8,16点）
-		count, quantity, value, longCount, shortCount, longValue, shortValue, periods := globalStats.GetPeriodStats(8)
-		log.Printf("[%s统计] UTC时间: %s, 过去8小时清算订单数: %d, 总数量: %.4f, 总价值: %.4f",
-			period, now.Format("2006-01-02 15:04"), count, quantity, value)
+	case "4小时", "8小时":
+		// 显示过去4/8小时的总统计（UTC固定时间点：4小时 0,4,8,12,16,20点，8小时 0,8,16点）
+		hours := 4
+		if period == "8小时" {
+			hours = 8
+		}
+		total, top, periods := globalStats.completedHours(now, hours, reportTopN)
+		log.Printf("[%s统计] UTC时间: %s, 过去%d小时清算订单数: %d, 总数量: %.4f, 总价值: %.4f",
+			period, now.Format("2006-01-02 15:04"), hours, total.Count, total.Quantity, total.Value)
 		log.Printf("   多单: %d笔 价值: %s USDT, 空单: %d笔 价值: %s USDT",
-			longCount, formatToWan(longValue), shortCount, formatToWan(shortValue))
+			total.LongCount, formatToWan(total.LongValue), total.ShortCount, formatToWan(total.ShortValue))
 		log.Printf("   包含时段: %v", periods)
 
-		// 发送8小时统计推送通知
-		sendStatsReport(period, count, quantity, value, longCount, shortCount, longValue, shortValue, now.Format("2006-01-02 15:04"))
+		// 发送4/8小时统计推送通知
+		sendStatsReport(period, total.Count, total.Quantity, total.Value, total.LongCount, total.ShortCount, total.LongValue, total.ShortValue, now.Format("2006-01-02 15:04"), top)
 
 	case "24小时":
-		// 显示当天从UTC零点开始的统计
-		count, quantity, value, longCount, shortCount, longValue, shortValue, timeKey := globalStats.GetTodayStats()
+		// 显示刚刚结束的UTC自然日统计
+		total, top, dayKey := globalStats.completedDay(now, reportTopN)
 		log.Printf("[%s统计] UTC日期: %s, 从零点开始清算订单数: %d, 总数量: %.4f, 总价值: %.4f",
-			period, timeKey, count, quantity, value)
+			period, dayKey, total.Count, total.Quantity, total.Value)
 		log.Printf("   多单: %d笔 价值: %s USDT, 空单: %d笔 价值: %s USDT",
-			longCount, formatToWan(longValue), shortCount, formatToWan(shortValue))
+			total.LongCount, formatToWan(total.LongValue), total.ShortCount, formatToWan(total.ShortValue))
 
 		// 发送24小时统计推送通知
-		sendStatsReport(period, count, quantity, value, longCount, shortCount, longValue, shortValue, timeKey)
+		sendStatsReport(period, total.Count, total.Quantity, total.Value, total.LongCount, total.ShortCount, total.LongValue, total.ShortValue, dayKey, top)
 
 		// UTC零点时清理旧数据
 		if now.Hour() == 0 && now.Minute() < 5 {
@@ -355,7 +385,7 @@ func logStats(period string) {
 }
 
 // 发送统计报告推送消息
-func sendStatsReport(period string, count int64, _ /*quantity*/, value float64, longCount, shortCount int64, longValue, shortValue float64, timeKey string) {
+func sendStatsReport(period string, count int64, _ /*quantity*/, value float64, longCount, shortCount int64, longValue, shortValue float64, timeKey string, top []SymbolStats) {
 	// 只有在有清算数据时才发送推送
 	if count == 0 {
 		return
@@ -388,10 +418,13 @@ func sendStatsReport(period string, count int64, _ /*quantity*/, value float64,
 		shortCount, shortPercent,
 		formatToWan(shortValue))
 
+	// 附带清算价值最高的交易对和资金费率/持仓量快照
+	message += formatTopSymbols(top)
+	message += fundingReport()
+
 	// 发送推送通知
 	go func() {
-		client := expo.GetExpoClient()
-		if client != nil && client.GetTokenCount() > 0 {
+		if notification.Available() {
 			err := notification.SendNotification(message, "liquidation")
 			if err != nil {
 				log.Printf("发送统计报告推送失败: %v", err)
@@ -505,6 +538,14 @@ func ForceReceive() {
 		// fmt.Println(event)
 		// 记录统计数据
 		globalStats.AddLiquidation(event)
+		// 清算记录批量入库
+		recordLiquidation(event)
+
+		order := parseLiquidation(event)
+		// 单笔大额清算实时告警
+		checkLargeLiquidation(order)
+		// 滑动窗口内的连环清算
+		cascades.add(order)
 	}
 
 	// 错误计数器，避免频繁发送错误通知
@@ -540,8 +581,7 @@ func ForceReceive() {
 					err,
 					errorCount)
 
-				client := expo.GetExpoClient()
-				if client != nil && client.GetTokenCount() > 0 {
+				if notification.Available() {
 					notifyErr := notification.SendNotificationWithTitle(message, "清算监控告警", "liquidation")
 					if notifyErr != nil {
 						log.Printf("发送错误通知失败: %v", notifyErr)
@@ -557,8 +597,7 @@ func ForceReceive() {
 					"系统将继续尝试重连",
 					now.Format("2006-01-02 15:04:05"))
 
-				client := expo.GetExpoClient()
-				if client != nil && client.GetTokenCount() > 0 {
+				if notification.Available() {
 					notification.SendNotificationWithTitle(message, "清算监控告警", "liquidation")
 				}
 			}
@@ -580,8 +619,7 @@ func ForceReceive() {
 				time.Now().Format("2006-01-02 15:04:05"),
 				err)
 
-			client := expo.GetExpoClient()
-			if client != nil && client.GetTokenCount() > 0 {
+			if notification.Available() {
 				notifyErr := notification.SendNotificationWithTitle(message, "清算监控启动失败", "liquidation")
 				if notifyErr != nil {
 					log.Printf("发送启动失败通知失败: %v", notifyErr)
@@ -0,0 +1,212 @@
+package margin_push
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportTopN 统计报告中列出的清算价值最高的交易对数量
+const reportTopN = 5
+
+// maxStatsHours 按小时查询的最长范围，与小时数据保留时间一致
+const maxStatsHours = 48
+
+// SymbolStats 单个交易对的清算统计
+type SymbolStats struct {
+	Symbol string `json:"symbol"`
+	PeriodStats
+}
+
+// HourlyStats 单个小时的清算统计
+type HourlyStats struct {
+	Hour string `json:"hour"` // UTC 小时，如 2024-01-01-15
+	PeriodStats
+}
+
+// LiquidationStats 最近若干小时的清算统计，按交易对排行并按小时细分
+type LiquidationStats struct {
+	Symbol  string        `json:"symbol,omitempty"`
+	Hours   int           `json:"hours"`
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	Total   PeriodStats   `json:"total"`
+	Symbols []SymbolStats `json:"symbols"`
+	Hourly  []HourlyStats `json:"hourly"`
+}
+
+// symbolStats 返回 key 时间段内交易对的统计，不存在时创建，调用方需持有写锁
+func symbolStats(buckets map[string]map[string]*PeriodStats, key, symbol string, start time.Time) *PeriodStats {
+	if buckets[key] == nil {
+		buckets[key] = make(map[string]*PeriodStats)
+	}
+	if buckets[key][symbol] == nil {
+		buckets[key][symbol] = &PeriodStats{StartTime: start}
+	}
+	return buckets[key][symbol]
+}
+
+// mergeSymbols 将一个时间段的交易对统计累加到 into
+func mergeSymbols(into map[string]*PeriodStats, bucket map[string]*PeriodStats) {
+	for symbol, stats := range bucket {
+		if into[symbol] == nil {
+			into[symbol] = &PeriodStats{StartTime: stats.StartTime}
+		}
+		into[symbol].merge(stats)
+	}
+}
+
+// rankSymbols 按清算价值从高到低排序，n <= 0 时返回全部
+func rankSymbols(bySymbol map[string]*PeriodStats, n int) []SymbolStats {
+	list := make([]SymbolStats, 0, len(bySymbol))
+	for symbol, stats := range bySymbol {
+		list = append(list, SymbolStats{Symbol: symbol, PeriodStats: *stats})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Value != list[j].Value {
+			return list[i].Value > list[j].Value
+		}
+		return list[i].Symbol < list[j].Symbol
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// completedHourKeys end 所在小时之前已结束的 hours 个小时，由近到远
+func completedHourKeys(end time.Time, hours int) []string {
+	last := end.UTC().Truncate(time.Hour)
+	keys := make([]string, 0, hours)
+	for i := 1; i <= hours; i++ {
+		keys = append(keys, last.Add(-time.Duration(i)*time.Hour).Format("2006-01-02-15"))
+	}
+	return keys
+}
+
+// completedHours 定时报告使用：end 之前已结束的 hours 个小时的总计和清算价值最高的 n 个交易对，
+// 总计与排行使用同一窗口，避免整点触发时读到刚开始的小时
+func (s *Stats) completedHours(end time.Time, hours, n int) (PeriodStats, []SymbolStats, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := completedHourKeys(end, hours)
+	var total PeriodStats
+	bySymbol := make(map[string]*PeriodStats)
+	for _, key := range keys {
+		if stats := s.hourlyStats[key]; stats != nil {
+			total.merge(stats)
+		}
+		mergeSymbols(bySymbol, s.hourlySymbolStats[key])
+	}
+	return total, rankSymbols(bySymbol, n), keys
+}
+
+// completedDay 定时报告使用：end 之前最近一个已结束小时所在 UTC 日的总计和排行，零点触发时即前一天
+func (s *Stats) completedDay(end time.Time, n int) (PeriodStats, []SymbolStats, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dayKey := end.UTC().Truncate(time.Hour).Add(-time.Hour).Format("2006-01-02")
+	var total PeriodStats
+	if stats := s.dailyStats[dayKey]; stats != nil {
+		total.merge(stats)
+	}
+	bySymbol := make(map[string]*PeriodStats)
+	mergeSymbols(bySymbol, s.dailySymbolStats[dayKey])
+	return total, rankSymbols(bySymbol, n), dayKey
+}
+
+// TopSymbols 最近 hours 个小时（含当前小时）清算价值最高的 n 个交易对
+func (s *Stats) TopSymbols(hours, n int) []SymbolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UTC()
+	bySymbol := make(map[string]*PeriodStats)
+	for i := 0; i < hours; i++ {
+		hourKey := now.Add(-time.Duration(i) * time.Hour).Format("2006-01-02-15")
+		mergeSymbols(bySymbol, s.hourlySymbolStats[hourKey])
+	}
+	return rankSymbols(bySymbol, n)
+}
+
+// TopSymbolsToday 当天从UTC零点开始清算价值最高的 n 个交易对
+func (s *Stats) TopSymbolsToday(n int) []SymbolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dayKey := time.Now().UTC().Format("2006-01-02")
+	bySymbol := make(map[string]*PeriodStats)
+	mergeSymbols(bySymbol, s.dailySymbolStats[dayKey])
+	return rankSymbols(bySymbol, n)
+}
+
+// Report 最近 hours 个小时的清算统计，symbol 不为空时只统计该交易对
+func (s *Stats) Report(symbol string, hours int) LiquidationStats {
+	if hours <= 0 || hours > maxStatsHours {
+		hours = 24
+	}
+	symbol = strings.ToUpper(symbol)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UTC()
+	report := LiquidationStats{
+		Symbol: symbol,
+		Hours:  hours,
+		From:   now.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour),
+		To:     now,
+		Hourly: make([]HourlyStats, 0, hours),
+	}
+	report.Total.StartTime = report.From
+
+	bySymbol := make(map[string]*PeriodStats)
+	// 按时间先后排列，便于客户端直接绘图
+	for i := hours - 1; i >= 0; i-- {
+		hour := now.Add(-time.Duration(i) * time.Hour)
+		hourKey := hour.Format("2006-01-02-15")
+		bucket := s.hourlySymbolStats[hourKey]
+
+		h := HourlyStats{Hour: hourKey, PeriodStats: PeriodStats{StartTime: hour.Truncate(time.Hour)}}
+		if symbol != "" {
+			if stats := bucket[symbol]; stats != nil {
+				h.merge(stats)
+				mergeSymbols(bySymbol, map[string]*PeriodStats{symbol: stats})
+			}
+		} else {
+			if stats := s.hourlyStats[hourKey]; stats != nil {
+				h.merge(stats)
+			}
+			mergeSymbols(bySymbol, bucket)
+		}
+		report.Total.merge(&h.PeriodStats)
+		report.Hourly = append(report.Hourly, h)
+	}
+	report.Symbols = rankSymbols(bySymbol, 0)
+	return report
+}
+
+// GetLiquidationStats 最近 hours 个小时的清算统计，hours 超出 1-48 时按 24 小时统计
+func GetLiquidationStats(symbol string, hours int) LiquidationStats {
+	return globalStats.Report(symbol, hours)
+}
+
+// formatTopSymbols 统计报告中的交易对排行
+func formatTopSymbols(top []SymbolStats) string {
+	if len(top) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(top))
+	for i, st := range top {
+		longPercent := float64(0)
+		if st.Value > 0 {
+			longPercent = st.LongValue / st.Value * 100
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s %s USDT (%d笔, 多单 %.0f%%)",
+			i+1, st.Symbol, formatToWan(st.Value), st.Count, longPercent))
+	}
+	return "\n━━━━━━━━━━━━━━━━\n🏆 清算排行\n" + strings.Join(lines, "\n")
+}
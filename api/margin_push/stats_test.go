@@ -0,0 +1,96 @@
+package margin_push
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func liquidationEvent(symbol string, side futures.SideType, price, quantity string) *futures.WsLiquidationOrderEvent {
+	return &futures.WsLiquidationOrderEvent{LiquidationOrder: futures.WsLiquidationOrder{
+		Symbol: symbol, Side: side, Price: price, OrigQuantity: quantity,
+	}}
+}
+
+func TestStatsPerSymbol(t *testing.T) {
+	s := NewStats()
+	s.AddLiquidation(liquidationEvent("BTCUSDT", "BUY", "40000", "1"))
+	s.AddLiquidation(liquidationEvent("btcusdt", "SELL", "40000", "0.5"))
+	s.AddLiquidation(liquidationEvent("ETHUSDT", "SELL", "2000", "5"))
+	s.AddLiquidation(liquidationEvent("SOLUSDT", "BUY", "100", "10"))
+
+	top := s.TopSymbols(1, 2)
+	if len(top) != 2 || top[0].Symbol != "BTCUSDT" || top[1].Symbol != "ETHUSDT" {
+		t.Fatalf("unexpected leaderboard %+v", top)
+	}
+	if btc := top[0]; btc.Count != 2 || btc.Value != 60000 || btc.LongValue != 20000 || btc.ShortCount != 1 {
+		t.Fatalf("unexpected BTCUSDT stats %+v", btc)
+	}
+	if today := s.TopSymbolsToday(0); len(today) != 3 {
+		t.Fatalf("expected 3 symbols today, got %+v", today)
+	}
+
+	text := formatTopSymbols(top)
+	if !strings.Contains(text, "1. BTCUSDT 6.00w USDT (2笔, 多单 33%)") || !strings.Contains(text, "2. ETHUSDT 1.00w USDT (1笔, 多单 100%)") {
+		t.Fatalf("unexpected leaderboard text %q", text)
+	}
+	if formatTopSymbols(nil) != "" {
+		t.Fatal("empty leaderboard should render nothing")
+	}
+}
+
+func TestStatsReport(t *testing.T) {
+	s := NewStats()
+	s.AddLiquidation(liquidationEvent("BTCUSDT", "BUY", "40000", "1"))
+	s.AddLiquidation(liquidationEvent("ETHUSDT", "SELL", "2000", "5"))
+
+	all := s.Report("", 4)
+	if all.Hours != 4 || len(all.Hourly) != 4 || all.Total.Count != 2 || all.Total.Value != 50000 || len(all.Symbols) != 2 {
+		t.Fatalf("unexpected market report %+v", all)
+	}
+	// 最后一个小时为当前小时
+	if last := all.Hourly[3]; last.Count != 2 || !last.StartTime.Equal(all.To.Truncate(time.Hour)) {
+		t.Fatalf("unexpected current hour %+v", last)
+	}
+
+	eth := s.Report("ethusdt", 0)
+	if eth.Symbol != "ETHUSDT" || eth.Hours != 24 || eth.Total.Count != 1 || eth.Total.LongValue != 10000 {
+		t.Fatalf("unexpected symbol report %+v", eth)
+	}
+	if len(eth.Symbols) != 1 || eth.Symbols[0].Symbol != "ETHUSDT" {
+		t.Fatalf("symbol report should only include the symbol, got %+v", eth.Symbols)
+	}
+}
+
+func TestCompletedHoursReport(t *testing.T) {
+	s := NewStats()
+	// 报告在 00:00 触发
+	end := time.Date(2024, 1, 2, 0, 0, 1, 0, time.UTC)
+	s.add("BTCUSDT", 1, 40000, true, end.Add(-30*time.Minute))
+	s.add("ETHUSDT", 5, 10000, false, end.Add(-3*time.Hour))
+	s.add("SOLUSDT", 10, 90000, true, end.Add(-5*time.Hour))
+	// 刚开始的小时不计入
+	s.add("DOGEUSDT", 1000, 500000, true, end)
+
+	total, top, keys := s.completedHours(end, 1, reportTopN)
+	if keys[0] != "2024-01-01-23" || total.Count != 1 || total.Value != 40000 || len(top) != 1 || top[0].Symbol != "BTCUSDT" {
+		t.Fatalf("unexpected 1h report %+v %+v %v", total, top, keys)
+	}
+
+	// 总计和排行使用同一窗口
+	total, top, keys = s.completedHours(end, 4, reportTopN)
+	var sum float64
+	for _, st := range top {
+		sum += st.Value
+	}
+	if len(keys) != 4 || total.Count != 2 || total.Value != 50000 || sum != total.Value || top[0].Symbol != "BTCUSDT" {
+		t.Fatalf("unexpected 4h report %+v %+v %v", total, top, keys)
+	}
+
+	total, top, dayKey := s.completedDay(end, reportTopN)
+	if dayKey != "2024-01-01" || total.Count != 3 || total.Value != 140000 || len(top) != 3 || top[0].Symbol != "SOLUSDT" {
+		t.Fatalf("unexpected daily report %s %+v %+v", dayKey, total, top)
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -31,10 +32,15 @@ func (l *Liquidation) BeforeCreate(tx *gorm.DB) error {
 	// 计算价值
 	l.Value = l.Quantity * l.Price
 	// 判断是否多单
-	l.IsLong = l.Side == "BUY"
+	l.IsLong = IsLongLiquidation(l.Side)
 	return nil
 }
 
+// IsLongLiquidation 根据强平单方向判断被清算的仓位：SELL 平掉的是多单，BUY 平掉的是空单
+func IsLongLiquidation(side string) bool {
+	return strings.EqualFold(side, "SELL")
+}
+
 // PushToken Expo推送令牌模型
 type PushToken struct {
 	gorm.Model
@@ -42,6 +48,7 @@ type PushToken struct {
 	DeviceInfo string    `gorm:"size:500" json:"device_info"`                // 设备信息
 	IsActive   bool      `gorm:"default:true;index" json:"is_active"`        // 是否活跃
 	LastUsed   time.Time `gorm:"index" json:"last_used"`                     // 最后使用时间
+	Topics     string    `gorm:"type:text" json:"topics"`                    // 订阅主题，逗号分隔，为空表示订阅全部
 }
 
 // TableName 指定表名
@@ -67,6 +74,50 @@ func (RSISignal) TableName() string {
 	return "rsi_signals"
 }
 
+// Candle K线记录模型，同一交易对/周期按收盘时间唯一
+type Candle struct {
+	gorm.Model
+	Symbol    string    `gorm:"size:20;not null;uniqueIndex:idx_candle_symbol_interval_close,priority:1" json:"symbol"`   // 交易对符号，大写
+	Interval  string    `gorm:"size:10;not null;uniqueIndex:idx_candle_symbol_interval_close,priority:2" json:"interval"` // 时间周期
+	OpenTime  time.Time `gorm:"not null;index" json:"open_time"`                                                          // 开盘时间
+	CloseTime time.Time `gorm:"not null;uniqueIndex:idx_candle_symbol_interval_close,priority:3" json:"close_time"`       // 收盘时间
+	Open      float64   `gorm:"type:decimal(30,12);not null" json:"open"`                                                 // 开盘价
+	High      float64   `gorm:"type:decimal(30,12);not null" json:"high"`                                                 // 最高价
+	Low       float64   `gorm:"type:decimal(30,12);not null" json:"low"`                                                  // 最低价
+	Close     float64   `gorm:"type:decimal(30,12);not null" json:"close"`                                                // 收盘价
+	Volume    float64   `gorm:"type:decimal(30,8)" json:"volume"`                                                         // 成交量
+
+	QuoteVolume         float64 `gorm:"type:decimal(30,8)" json:"quote_volume"`           // 成交额 (USDT)
+	TakerBuyVolume      float64 `gorm:"type:decimal(30,8)" json:"taker_buy_volume"`       // 主动买入成交量
+	TakerBuyQuoteVolume float64 `gorm:"type:decimal(30,8)" json:"taker_buy_quote_volume"` // 主动买入成交额
+}
+
+// TableName 指定表名
+func (Candle) TableName() string {
+	return "candles"
+}
+
+// PriceAlert 用户创建的价格告警，只推送给创建它的 token
+type PriceAlert struct {
+	gorm.Model
+	Token         string     `gorm:"size:500;not null;index" json:"token"`        // Expo推送令牌
+	Symbol        string     `gorm:"size:20;not null;index" json:"symbol"`        // 交易对符号，大写
+	Type          string     `gorm:"size:10;not null" json:"type"`                // above/below/change
+	Price         float64    `gorm:"type:decimal(30,12)" json:"price,omitempty"`  // above/below 的目标价格
+	Percent       float64    `gorm:"type:decimal(10,4)" json:"percent,omitempty"` // change 的涨跌幅（%）
+	Direction     string     `gorm:"size:10" json:"direction,omitempty"`          // change 的方向: up/down，为空表示双向
+	Window        int64      `json:"window,omitempty"`                            // change 的时间窗口（秒）
+	Recurring     bool       `gorm:"default:false" json:"recurring"`              // 循环告警，否则触发一次后失效
+	Active        bool       `gorm:"default:true;index" json:"active"`            // 是否有效
+	Note          string     `gorm:"size:200" json:"note,omitempty"`              // 备注，附在告警正文中
+	TriggerCount  int        `gorm:"default:0" json:"trigger_count"`              // 已触发次数
+	LastTriggered *time.Time `json:"last_triggered,omitempty"`                    // 最后触发时间
+}
+
+// TableName 指定表名
+func (PriceAlert) TableName() string {
+	return "price_alerts"
+}
 // NewsArticle 新闻文章模型
 type NewsArticle struct {
 	gorm.Model
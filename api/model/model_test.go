@@ -32,6 +32,8 @@ func setupTestDB(t *testing.T) {
 		&NewsArticle{},
 		&MessageLog{},
 		&LiquidationStats{},
+		&Candle{},
+		&PriceAlert{},
 	)
 	if err != nil {
 		t.Fatalf("自动迁移失败: %v", err)
@@ -53,6 +55,8 @@ func teardownTestDB(t *testing.T) {
 		&NewsArticle{},
 		&MessageLog{},
 		&LiquidationStats{},
+		&Candle{},
+		&PriceAlert{},
 	)
 
 	database.CloseDB()
@@ -88,8 +92,8 @@ func TestLiquidationModel(t *testing.T) {
 			t.Errorf("Value = %f, want %f", liq.Value, 1.5*50000.0)
 		}
 
-		if !liq.IsLong {
-			t.Error("IsLong 应该为 true (BUY)")
+		if liq.IsLong {
+			t.Error("IsLong 应该为 false (BUY 平掉的是空单)")
 		}
 	})
 
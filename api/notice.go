@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"notice/api/config"
@@ -15,8 +16,11 @@ import (
 	"notice/api/expo"
 	"notice/api/listen"
 	"notice/api/margin_push"
+	"notice/api/model"
+	"notice/api/notification"
 	"notice/api/rsi"
 	"notice/api/storage"
+	"notice/api/topic"
 
 	"notice/api/websocket"
 
@@ -41,22 +45,63 @@ func main() {
 	}
 
 	// 初始化数据库连接
+	dbReady := false
 	if c.Database.Host != "" {
 		if err := database.InitDB(c.Database); err != nil {
 			logx.Errorf("Failed to initialize database: %v", err)
 			// 如果数据库连接失败，记录错误但不中断程序启动
 		} else {
 			logx.Info("Database initialized successfully")
-			// 可以在这里执行数据库表迁移
-			// err := database.AutoMigrate(&YourModel{})
-			// if err != nil {
-			//     logx.Errorf("Failed to migrate database: %v", err)
-			// }
+			dbReady = true
+			// 执行数据库表迁移
+			err := database.AutoMigrate(&model.PushToken{}, &model.RSISignal{}, &model.Candle{}, &model.PriceAlert{}, &model.Liquidation{}, &model.LiquidationStats{})
+			if err != nil {
+				logx.Errorf("Failed to migrate database: %v", err)
+			}
 		}
 	} else {
 		logx.Info("Database configuration not found, skipping database initialization")
 	}
 
+	// 初始化推送 token 存储：优先使用数据库，否则回退到本地文件
+	var tokenStore expo.TokenStore
+	if dbReady {
+		tokenStore = expo.NewDBTokenStore(database.GetDB())
+	} else {
+		tokenStore = expo.NewFileTokenStore("./storage/push_tokens.json")
+	}
+	if err := expo.GetExpoClient().InitTokenStore(tokenStore); err != nil {
+		logx.Errorf("Failed to load push tokens: %v", err)
+	}
+	// 后台轮询推送回执，自动清理失效 token
+	expo.GetExpoClient().StartReceiptPoller(5*time.Minute, 15*time.Minute)
+
+	// 初始化通知渠道（Expo/Discord/Slack/Email）
+	notification.Init(c.Notifiers)
+
+	// RSI 阈值穿越告警配置，数据库可用时记录信号
+	rsi.SetAlertConfig(c.RSIAlert)
+	rsi.SetConfluenceRules(c.Confluence)
+	rsi.SetPatternConfig(c.Patterns)
+	rsi.SetVolumeConfig(c.Volume)
+	rsi.SetKlineConfig(c.Klines)
+	if dbReady {
+		rsi.SetSignalStore(database.GetDB())
+		// K线统一存入 candles 表，替代本地文件缓存
+		rsi.SetCandleStore(rsi.NewDBCandleStore(database.GetDB()))
+	}
+
+	// 价格告警：数据库可用时存入 price_alerts 表，否则回退到本地文件
+	var priceAlertStore rsi.PriceAlertStore
+	if dbReady {
+		priceAlertStore = rsi.NewDBPriceAlertStore(database.GetDB())
+	} else {
+		priceAlertStore = rsi.NewFilePriceAlertStore("./storage/price_alerts.json")
+	}
+	if err := rsi.InitPriceAlerts(c.PriceAlerts, priceAlertStore); err != nil {
+		logx.Errorf("Failed to load price alerts: %v", err)
+	}
+
 	// 直接写死的WebSocket连接配置
 	hardcodedWSConfigs := []config.WebSocketConfig{
 		{
@@ -110,6 +155,8 @@ func main() {
 			logx.Infof("Closing WebSocket connector: %s", name)
 			connector.Close()
 		}
+		// 写入缓冲中的清算记录后再关闭数据库
+		margin_push.StopLiquidationStore()
 		// 关闭数据库连接
 		if err := database.CloseDB(); err != nil {
 			logx.Errorf("Failed to close database: %v", err)
@@ -149,7 +196,11 @@ func main() {
 				return
 			}
 
-			err := expo.GetExpoClient().AddToken(token)
+			// 订阅主题，支持多个 topics 参数或逗号分隔，为空表示订阅全部
+			r.ParseForm()
+			topics := topic.Parse(r.Form["topics"]...)
+
+			err := expo.GetExpoClient().AddTokenWithDevice(token, r.FormValue("device_info"), topics...)
 			if err != nil {
 				if err.Error() == "token already exists" {
 					w.WriteHeader(http.StatusConflict)
@@ -216,6 +267,97 @@ func main() {
 			w.Write([]byte(fmt.Sprintf(`{"total_tokens": %d,"tokens":%v}`, count, expo.GetExpoClient().GetTokens())))
 		},
 	})
+	// 获取 token 订阅主题
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice_token/topics",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Token is required"))
+				return
+			}
+
+			topics, err := expo.GetExpoClient().GetTopics(token)
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(err.Error()))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success":       true,
+				"token":         token,
+				"topics":        topics,
+				"subscribe_all": len(topics) == 0,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 更新 token 订阅主题（整体替换，topics 为空表示订阅全部）
+	server.AddRoute(rest.Route{
+		Method: http.MethodPut,
+		Path:   "/notice_token/topics",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			token := r.FormValue("token")
+			if token == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Token is required"))
+				return
+			}
+
+			topics := topic.Parse(r.Form["topics"]...)
+			if err := expo.GetExpoClient().SetTopics(token, topics); err != nil {
+				if err.Error() == "token not found" {
+					w.WriteHeader(http.StatusNotFound)
+				} else {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+				w.Write([]byte(err.Error()))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success":       true,
+				"token":         token,
+				"topics":        topics,
+				"subscribe_all": len(topics) == 0,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取 token 推送投递历史
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice_token/deliveries",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			client := expo.GetExpoClient()
+			response := map[string]interface{}{
+				"success":          true,
+				"pending_receipts": client.GetPendingReceiptCount(),
+			}
+			if token := r.URL.Query().Get("token"); token != "" {
+				history := client.GetDeliveryHistory(token)
+				response["count"] = len(history)
+				response["data"] = history
+			} else {
+				response["data"] = client.GetAllDeliveryHistory()
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
 	server.AddRoute(rest.Route{
 		Method: http.MethodPost,
 		Path:   "/notice/query",
@@ -236,13 +378,8 @@ func main() {
 			// 记录信号日志
 			logx.Infof("Signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), data)
 
-			// 保存消息到存储
-			err := storage.GetMessageStorage().SaveMessage(data, "manual")
-			if err != nil {
-				logx.Errorf("Failed to save message to storage: %v", err)
-			}
-
-			err = expo.GetExpoClient().Send(data)
+			// 发送到所有通知渠道并保存到存储
+			err := notification.SendNotification(data, "manual")
 			if err != nil {
 				logx.Errorf("Failed to send signal: %s, error: %v", data, err)
 				w.WriteHeader(http.StatusInternalServerError)
@@ -341,14 +478,8 @@ func main() {
 			// 记录准备发送的信号
 			logx.Infof("Webhook signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), message)
 
-			// 保存消息到存储
-			err := storage.GetMessageStorage().SaveMessage(message, "webhook")
-			if err != nil {
-				logx.Errorf("Failed to save webhook message to storage: %v", err)
-			}
-
-			// Send notification
-			err = expo.GetExpoClient().Send(message)
+			// Send notification to all channels and save to storage
+			err := notification.SendNotification(message, "webhook")
 			if err != nil {
 				logx.Errorf("Failed to send webhook signal: %s, error: %v", message, err)
 				w.WriteHeader(http.StatusInternalServerError)
@@ -361,6 +492,388 @@ func main() {
 		},
 	})
 
+	// 获取通知渠道发送统计
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/notifiers/stats",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]interface{}{
+				"success": true,
+				"data":    notification.GetRegistry().Stats(),
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取最新一次收盘的 RSI 读数
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/rsi",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			readings := rsi.GetLatestReadings(r.URL.Query().Get("symbol"))
+			response := map[string]interface{}{
+				"success": true,
+				"data":    readings,
+				"count":   len(readings),
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取历史K线，优先读取本地存储，只从 REST 补齐最新的K线
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/candles",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			symbol := r.URL.Query().Get("symbol")
+			interval := topic.Normalize(r.URL.Query().Get("interval"))
+			if symbol == "" || interval == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("symbol and interval are required"))
+				return
+			}
+
+			limit := 500
+			if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+				var err error
+				limit, err = strconv.Atoi(limitStr)
+				if err != nil || limit <= 0 || limit > 5000 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid limit parameter, expect 1-5000"))
+					return
+				}
+			}
+
+			candles, err := rsi.FetchKlines(symbol, interval, limit)
+			if err != nil {
+				logx.Errorf("Failed to get candles: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Failed to retrieve candles"))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success":  true,
+				"symbol":   strings.ToUpper(symbol),
+				"interval": interval,
+				"data":     candles,
+				"count":    len(candles),
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取运行中的 RSI 监控及状态
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/watch",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			list := rsi.GetWatchers().List()
+			response := map[string]interface{}{
+				"success": true,
+				"data":    list,
+				"count":   len(list),
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 运行时添加 RSI 监控，intervals/indicators 支持多个参数或逗号分隔
+	server.AddRoute(rest.Route{
+		Method: http.MethodPost,
+		Path:   "/notice/watch",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			watch := config.WatchConfig{
+				Symbol:    r.FormValue("symbol"),
+				Intervals: topic.Parse(r.Form["intervals"]...),
+			}
+			if watch.Symbol == "" || len(watch.Intervals) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("symbol and intervals are required"))
+				return
+			}
+			if err := rsi.ValidateWatch(watch.Symbol, watch.Intervals); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			watch.Period, _ = strconv.Atoi(r.FormValue("period"))
+			watch.Overbought, _ = strconv.ParseFloat(r.FormValue("overbought"), 64)
+			watch.Oversold, _ = strconv.ParseFloat(r.FormValue("oversold"), 64)
+			watch.Hysteresis, _ = strconv.ParseFloat(r.FormValue("hysteresis"), 64)
+			watch.Warmup, _ = strconv.Atoi(r.FormValue("warmup"))
+			// 指标使用默认参数，如 indicators=rsi,macd,ema_cross,bollinger
+			for _, t := range topic.Parse(r.Form["indicators"]...) {
+				watch.Indicators = append(watch.Indicators, config.IndicatorConfig{Type: t})
+			}
+
+			started, err := rsi.GetWatchers().Add(watch)
+			if err != nil && len(started) == 0 {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			// 资金费率/持仓量监控跟随 Watchlist
+			if m := margin_push.GetFundingMonitor(); m != nil {
+				m.AddSymbol(watch.Symbol)
+			}
+
+			response := map[string]interface{}{
+				"success": true,
+				"data":    started,
+			}
+			if err != nil {
+				response["error"] = err.Error()
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 停止 RSI 监控
+	server.AddRoute(rest.Route{
+		Method: http.MethodDelete,
+		Path:   "/notice/watch",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			symbol := r.FormValue("symbol")
+			interval := r.FormValue("interval")
+			if symbol == "" || interval == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("symbol and interval are required"))
+				return
+			}
+
+			if err := rsi.GetWatchers().Stop(symbol, topic.Normalize(interval)); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(err.Error()))
+				return
+			}
+			// 交易对的所有周期都停止后，资金费率/持仓量监控也不再跟踪
+			if m := margin_push.GetFundingMonitor(); m != nil && !rsi.GetWatchers().Watching(symbol) {
+				m.RemoveSymbol(symbol)
+			}
+
+			response := map[string]interface{}{
+				"success":  true,
+				"symbol":   symbol,
+				"interval": interval,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 创建价格告警：above/below 为价格穿越，change 为时间窗口内涨跌幅
+	server.AddRoute(rest.Route{
+		Method: http.MethodPost,
+		Path:   "/notice/alerts",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			manager := rsi.GetPriceAlerts()
+			if manager == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Price alerts are disabled"))
+				return
+			}
+
+			alert := model.PriceAlert{
+				Token:     r.FormValue("token"),
+				Symbol:    r.FormValue("symbol"),
+				Type:      r.FormValue("type"),
+				Direction: r.FormValue("direction"),
+				Note:      r.FormValue("note"),
+			}
+			alert.Price, _ = strconv.ParseFloat(r.FormValue("price"), 64)
+			alert.Percent, _ = strconv.ParseFloat(r.FormValue("percent"), 64)
+			alert.Recurring, _ = strconv.ParseBool(r.FormValue("recurring"))
+			if windowStr := r.FormValue("window"); windowStr != "" {
+				window, err := time.ParseDuration(windowStr)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid window parameter, expect duration like 15m or 1h"))
+					return
+				}
+				alert.Window = int64(window / time.Second)
+			}
+
+			created, err := manager.Create(alert)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success": true,
+				"data":    created,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取 token 的价格告警
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/alerts",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			manager := rsi.GetPriceAlerts()
+			if manager == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Price alerts are disabled"))
+				return
+			}
+
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Token is required"))
+				return
+			}
+
+			alerts, err := manager.List(token)
+			if err != nil {
+				logx.Errorf("Failed to list price alerts: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Failed to retrieve price alerts"))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success": true,
+				"data":    alerts,
+				"count":   len(alerts),
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 删除价格告警，只能删除 token 自己的告警
+	server.AddRoute(rest.Route{
+		Method: http.MethodDelete,
+		Path:   "/notice/alerts",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			manager := rsi.GetPriceAlerts()
+			if manager == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Price alerts are disabled"))
+				return
+			}
+
+			token := r.FormValue("token")
+			id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+			if token == "" || err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("token and id are required"))
+				return
+			}
+
+			if err := manager.Delete(uint(id), token); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(err.Error()))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success": true,
+				"id":      id,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 获取清算统计：按交易对排行并按小时细分，symbol 为空时统计全市场
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/liquidations/stats",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			hours := 24
+			if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+				var err error
+				hours, err = strconv.Atoi(hoursStr)
+				if err != nil || hours <= 0 || hours > 48 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("Invalid hours parameter, expect 1-48"))
+					return
+				}
+			}
+
+			stats := margin_push.GetLiquidationStats(r.URL.Query().Get("symbol"), hours)
+			response := map[string]interface{}{
+				"success": true,
+				"data":    stats,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
+	// 查询清算记录：按交易对、方向、最小价值和时间范围过滤，按时间或价值排序，游标分页
+	server.AddRoute(rest.Route{
+		Method: http.MethodGet,
+		Path:   "/notice/liquidations",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			store := margin_push.GetLiquidationStore()
+			if store == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Liquidation history requires database"))
+				return
+			}
+
+			query, err := margin_push.ParseLiquidationQuery(r.URL.Query())
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+
+			page, err := margin_push.QueryLiquidations(store, query)
+			if err != nil {
+				logx.Errorf("Failed to query liquidations: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("Failed to retrieve liquidations"))
+				return
+			}
+
+			response := map[string]interface{}{
+				"success":     true,
+				"count":       len(page.Data),
+				"data":        page.Data,
+				"next_cursor": page.NextCursor,
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		},
+	})
+
 	// 获取消息历史记录API
 	server.AddRoute(rest.Route{
 		Method: http.MethodGet,
@@ -505,18 +1018,26 @@ func main() {
 
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("启动清算订单监控程序...")
+	margin_push.SetPushConfig(c.Liquidation)
+	// 数据库可用时清算记录和统计入库，并在启动时恢复统计
+	if dbReady {
+		if err := margin_push.InitLiquidationStore(margin_push.NewDBLiquidationStore(database.GetDB())); err != nil {
+			log.Printf("恢复清算统计失败: %v", err)
+		}
+	}
 	go margin_push.ForceReceive()
-	// 启动币安 RSI 任务（BTCUSDT 1m/5m/15m/1h，RSI(14)）- 修改为更短周期便于测试
-	go rsi.StartBinanceRSI("btcusdt", "2h", 14)
-	go rsi.StartBinanceRSI("btcusdt", "4h", 14)
-	go rsi.StartBinanceRSI("btcusdt", "1d", 14)
-	go rsi.StartBinanceRSI("btcusdt", "1w", 14)
-	go rsi.StartBinanceRSI("btcusdt", "1M", 14)
-	go rsi.StartBinanceRSI("ethusdt", "2h", 14)
-	go rsi.StartBinanceRSI("ethusdt", "4h", 14)
-	go rsi.StartBinanceRSI("ethusdt", "1d", 14)
-	go rsi.StartBinanceRSI("ethusdt", "1w", 14)
-	go rsi.StartBinanceRSI("ethusdt", "1M", 14)
+	// 资金费率/持仓量监控，默认监控 Watchlist 中的交易对
+	watchlist := c.Watchlist
+	if len(watchlist) == 0 {
+		watchlist = rsi.DefaultWatchlist()
+	}
+	var watchSymbols []string
+	for _, w := range watchlist {
+		watchSymbols = append(watchSymbols, w.Symbol)
+	}
+	margin_push.StartFundingMonitor(c.Funding, watchSymbols)
+	// 按配置的监控列表启动币安 RSI 任务
+	rsi.StartWatchlist(c.Watchlist)
 
 	go listen.StartListen()
 	logx.Infof("Server starting on %s:%d", c.Host, c.Port)
@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+
+	"notice/api/config"
+)
+
+// EmailNotifier 通过 SMTP 发送邮件通知
+type EmailNotifier struct {
+	cfg config.EmailNotifierConfig
+}
+
+// NewEmailNotifier 创建邮件通知渠道
+func NewEmailNotifier(cfg config.EmailNotifierConfig) *EmailNotifier {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (e *EmailNotifier) Notify(title, message, source string) error {
+	if len(e.cfg.To) == 0 {
+		return fmt.Errorf("email recipients not configured")
+	}
+
+	// 消息本身可能带有 HTML 标签，以 HTML 格式发送并保留换行
+	body := strings.ReplaceAll(message, "\n", "<br>")
+	msg := strings.Join([]string{
+		"From: " + e.cfg.From,
+		"To: " + strings.Join(e.cfg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", fmt.Sprintf("[%s] %s", source, title)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/html; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", e.cfg.Host, e.cfg.Port)
+	return smtp.SendMail(addr, auth, e.cfg.From, e.cfg.To, []byte(msg))
+}
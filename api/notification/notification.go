@@ -1,44 +1,62 @@
 package notification
 
 import (
-	"notice/api/expo"
 	"notice/api/storage"
+	"notice/api/topic"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// defaultTitle 未指定标题时使用的通知标题
+const defaultTitle = "Rsi_signal"
+
 // SendNotification 发送通知并保存到存储
+// source 可以是消息来源（如 news），也可以是带交易对/周期的主题（如 rsi:btcusdt:4h，见 topic.Of），
+// Expo 只推送给订阅了该主题的 token
 func SendNotification(message, source string) error {
-	// 保存消息到存储
-	err := storage.GetMessageStorage().SaveMessage(message, source)
-	if err != nil {
-		logx.Errorf("Failed to save message to storage: %v", err)
-	}
-
-	// 发送推送通知
-	return expo.GetExpoClient().Send(message)
+	return send(message, defaultTitle, source, 0)
 }
 
 // SendNotificationWithTitle 发送带标题的通知并保存到存储
 func SendNotificationWithTitle(message, title, source string) error {
-	// 保存消息到存储
-	err := storage.GetMessageStorage().SaveMessage(message, source)
-	if err != nil {
-		logx.Errorf("Failed to save message to storage: %v", err)
-	}
-
-	// 发送推送通知
-	return expo.GetExpoClient().SendWithCustomTitle(message, title)
+	return send(message, title, source, 0)
 }
 
 // SendNotificationWithRetry 发送通知并保存到存储（带重试）
 func SendNotificationWithRetry(message, source string, maxRetries int) error {
-	// 保存消息到存储
-	err := storage.GetMessageStorage().SaveMessage(message, source)
-	if err != nil {
-		logx.Errorf("Failed to save message to storage: %v", err)
+	return send(message, defaultTitle, source, maxRetries)
+}
+
+// send 分发到所有启用的通知渠道，并将消息与各渠道结果一起保存到存储；
+// 定向主题只推送给所属用户，不写入公开的消息记录
+func send(message, title, source string, maxRetries int) error {
+	owned := topicOwned(source)
+	results, err := dispatch(title, message, source, maxRetries)
+	if owned {
+		return err
 	}
 
-	// 发送推送通知
-	return expo.GetExpoClient().SendWithRetry(message, maxRetries)
-}
\ No newline at end of file
+	deliveries := make([]storage.ChannelDelivery, 0, len(results))
+	for _, res := range results {
+		deliveries = append(deliveries, storage.ChannelDelivery{
+			Channel: res.Channel,
+			Success: res.Success,
+			Error:   res.Error,
+		})
+	}
+
+	// 保存消息到存储，source 按主题的第一段记录，便于按来源查询
+	record := storage.MessageRecord{
+		Message:    message,
+		Source:     topic.Source(source),
+		Deliveries: deliveries,
+	}
+	if record.Source != source {
+		record.Topic = source
+	}
+	if saveErr := storage.GetMessageStorage().SaveRecord(record); saveErr != nil {
+		logx.Errorf("Failed to save message to storage: %v", saveErr)
+	}
+
+	return err
+}
@@ -0,0 +1,248 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/expo"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Notifier 通知渠道接口
+type Notifier interface {
+	// Name 渠道名称，如 expo/telegram/discord
+	Name() string
+	// Notify 发送通知，source 为消息来源（rsi/liquidation/news 等）
+	Notify(title, message, source string) error
+}
+
+// retryNotifier 支持指定重试次数的渠道
+type retryNotifier interface {
+	NotifyWithRetry(title, message, source string, maxRetries int) error
+}
+
+// availableNotifier 可暂时不可用的渠道（如没有注册任何推送 token），不可用时跳过发送
+type availableNotifier interface {
+	Available() bool
+}
+
+// ownerNotifier 能将定向主题只推送给所属用户的渠道（如 Expo），定向主题只发往这类渠道
+type ownerNotifier interface {
+	HasTopicOwner(t string) bool
+}
+
+// topicOwned 主题是否为定向主题（如用户的价格告警），测试中可替换
+var topicOwned = func(source string) bool {
+	return expo.GetExpoClient().HasTopicOwner(source)
+}
+
+// DeliveryResult 单个渠道的发送结果
+type DeliveryResult struct {
+	Channel  string        `json:"channel"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ChannelStats 单个渠道的累计发送统计
+type ChannelStats struct {
+	Channel     string    `json:"channel"`
+	Sent        int64     `json:"sent"`
+	Failed      int64     `json:"failed"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
+}
+
+// Registry 通知渠道注册表
+type Registry struct {
+	mu        sync.RWMutex
+	notifiers []Notifier
+	stats     map[string]*ChannelStats
+}
+
+// NewRegistry 创建空的渠道注册表
+func NewRegistry() *Registry {
+	return &Registry{stats: make(map[string]*ChannelStats)}
+}
+
+// Register 注册通知渠道
+func (r *Registry) Register(n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifiers = append(r.notifiers, n)
+	r.stats[n.Name()] = &ChannelStats{Channel: n.Name()}
+}
+
+// Channels 返回已注册的渠道名称
+func (r *Registry) Channels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.notifiers))
+	for _, n := range r.notifiers {
+		names = append(names, n.Name())
+	}
+	return names
+}
+
+// Available 是否至少有一个渠道当前可用
+func (r *Registry) Available() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, n := range r.notifiers {
+		if a, ok := n.(availableNotifier); !ok || a.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatch 并发向所有可用渠道发送通知，返回每个渠道的结果；
+// 定向主题属于单个用户，不发往 Telegram/Discord/Slack/邮件等共享渠道
+func (r *Registry) Dispatch(title, message, source string, maxRetries int) []DeliveryResult {
+	owned := topicOwned(source)
+
+	r.mu.RLock()
+	var targets []Notifier
+	for _, n := range r.notifiers {
+		if a, ok := n.(availableNotifier); ok && !a.Available() {
+			continue
+		}
+		if _, ok := n.(ownerNotifier); owned && !ok {
+			continue
+		}
+		targets = append(targets, n)
+	}
+	r.mu.RUnlock()
+
+	results := make([]DeliveryResult, len(targets))
+	var wg sync.WaitGroup
+	for i, n := range targets {
+		wg.Add(1)
+		go func(i int, n Notifier) {
+			defer wg.Done()
+			start := time.Now()
+			var err error
+			if rn, ok := n.(retryNotifier); ok && maxRetries > 0 {
+				err = rn.NotifyWithRetry(title, message, source, maxRetries)
+			} else {
+				err = n.Notify(title, message, source)
+			}
+			results[i] = DeliveryResult{Channel: n.Name(), Success: err == nil, Duration: time.Since(start)}
+			if err != nil {
+				results[i].Error = err.Error()
+				logx.Errorf("Notifier [%s] failed to send %s message: %v", n.Name(), source, err)
+			}
+		}(i, n)
+	}
+	wg.Wait()
+
+	r.record(results)
+	return results
+}
+
+// record 更新渠道统计
+func (r *Registry) record(results []DeliveryResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, res := range results {
+		st, ok := r.stats[res.Channel]
+		if !ok {
+			continue
+		}
+		if res.Success {
+			st.Sent++
+			st.LastSuccess = now
+		} else {
+			st.Failed++
+			st.LastError = res.Error
+			st.LastFailure = now
+		}
+	}
+}
+
+// Stats 返回所有渠道的发送统计
+func (r *Registry) Stats() []ChannelStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make([]ChannelStats, 0, len(r.notifiers))
+	for _, n := range r.notifiers {
+		stats = append(stats, *r.stats[n.Name()])
+	}
+	return stats
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   *Registry
+)
+
+// GetRegistry 获取全局渠道注册表，未调用 Init 时默认只启用 Expo
+func GetRegistry() *Registry {
+	registryMu.RLock()
+	r := registry
+	registryMu.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if registry == nil {
+		registry = NewRegistry()
+		registry.Register(expo.GetExpoClient())
+	}
+	return registry
+}
+
+// Init 根据配置初始化通知渠道，应在程序启动时调用
+func Init(cfg config.NotifiersConfig) {
+	r := NewRegistry()
+	if !cfg.Expo.Disabled {
+		r.Register(expo.GetExpoClient())
+	}
+	if cfg.Telegram.Enabled {
+		r.Register(NewTelegramNotifier(cfg.Telegram))
+	}
+	if cfg.Discord.Enabled {
+		r.Register(NewDiscordNotifier(cfg.Discord.WebhookURL))
+	}
+	if cfg.Slack.Enabled {
+		r.Register(NewSlackNotifier(cfg.Slack.WebhookURL))
+	}
+	if cfg.Email.Enabled {
+		r.Register(NewEmailNotifier(cfg.Email))
+	}
+
+	registryMu.Lock()
+	registry = r
+	registryMu.Unlock()
+	logx.Infof("Notifiers initialized: %v", r.Channels())
+}
+
+// Available 是否至少有一个通知渠道可用
+func Available() bool {
+	return GetRegistry().Available()
+}
+
+// dispatch 发送通知到所有渠道；全部渠道失败时返回错误
+func dispatch(title, message, source string, maxRetries int) ([]DeliveryResult, error) {
+	results := GetRegistry().Dispatch(title, message, source, maxRetries)
+	if len(results) == 0 {
+		return results, fmt.Errorf("no notification channel available")
+	}
+
+	var errs []string
+	for _, res := range results {
+		if res.Success {
+			return results, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", res.Channel, res.Error))
+	}
+	return results, fmt.Errorf("all notification channels failed: %v", errs)
+}
@@ -0,0 +1,146 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"notice/api/config"
+)
+
+// fakeNotifier 测试用通知渠道
+type fakeNotifier struct {
+	name      string
+	err       error
+	available bool
+	sent      []string
+}
+
+func (f *fakeNotifier) Name() string    { return f.name }
+func (f *fakeNotifier) Available() bool { return f.available }
+func (f *fakeNotifier) Notify(title, message, source string) error {
+	f.sent = append(f.sent, message)
+	return f.err
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	ok := &fakeNotifier{name: "ok", available: true}
+	broken := &fakeNotifier{name: "broken", available: true, err: errors.New("boom")}
+	idle := &fakeNotifier{name: "idle"}
+
+	r := NewRegistry()
+	r.Register(ok)
+	r.Register(broken)
+	r.Register(idle)
+
+	results := r.Dispatch("title", "hello", "rsi", 0)
+	if len(results) != 2 {
+		t.Fatalf("Dispatch() returned %d results, want 2 (idle skipped)", len(results))
+	}
+	if len(ok.sent) != 1 || len(broken.sent) != 1 || len(idle.sent) != 0 {
+		t.Errorf("sent ok=%d broken=%d idle=%d, want 1/1/0", len(ok.sent), len(broken.sent), len(idle.sent))
+	}
+
+	stats := map[string]ChannelStats{}
+	for _, st := range r.Stats() {
+		stats[st.Channel] = st
+	}
+	if stats["ok"].Sent != 1 || stats["ok"].Failed != 0 {
+		t.Errorf("ok stats = %+v", stats["ok"])
+	}
+	if stats["broken"].Failed != 1 || stats["broken"].LastError != "boom" {
+		t.Errorf("broken stats = %+v", stats["broken"])
+	}
+}
+
+// fakeOwnerNotifier 测试用的定向推送渠道
+type fakeOwnerNotifier struct {
+	fakeNotifier
+}
+
+func (f *fakeOwnerNotifier) HasTopicOwner(t string) bool { return true }
+
+func TestRegistryDispatchOwnedTopic(t *testing.T) {
+	prev := topicOwned
+	topicOwned = func(source string) bool { return strings.HasPrefix(source, "price:") }
+	t.Cleanup(func() { topicOwned = prev })
+
+	var mu sync.Mutex
+	var hits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	owner := &fakeOwnerNotifier{fakeNotifier{name: "expo", available: true}}
+	r := NewRegistry()
+	r.Register(owner)
+	r.Register(NewTelegramNotifier(config.TelegramNotifierConfig{APIURL: srv.URL, BotToken: "t", DefaultChatID: "-100"}))
+	r.Register(NewDiscordNotifier(srv.URL + "/discord"))
+	r.Register(NewSlackNotifier(srv.URL + "/slack"))
+
+	results := r.Dispatch("价格告警", "BTCUSDT 上穿 100000", "price:btcusdt:12", 0)
+	if len(results) != 1 || results[0].Channel != "expo" || len(owner.sent) != 1 {
+		t.Fatalf("owned topic should only reach the owner notifier, got %+v", results)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("owned topic leaked to shared channels: %v", hits)
+	}
+
+	// 普通主题照常发往所有渠道
+	if results := r.Dispatch("RSI", "hello", "rsi:btcusdt:4h", 0); len(results) != 4 || len(hits) != 3 {
+		t.Fatalf("public topic should reach every channel, got %d results, %d hits", len(results), len(hits))
+	}
+}
+
+func TestInitConcurrentWithSend(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = nil
+		registryMu.Unlock()
+	})
+
+	cfg := config.NotifiersConfig{}
+	cfg.Expo.Disabled = true
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Init(cfg)
+		}()
+		go func() {
+			defer wg.Done()
+			Available()
+		}()
+	}
+	wg.Wait()
+
+	if channels := GetRegistry().Channels(); len(channels) != 0 {
+		t.Errorf("Channels() = %v, want none", channels)
+	}
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := NewDiscordNotifier(srv.URL).Notify("新闻", "<b>标题</b>\n内容 &amp; 细节", "news")
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if want := "**新闻**\n标题\n内容 & 细节"; payload["content"] != want {
+		t.Errorf("content = %q, want %q", payload["content"], want)
+	}
+}
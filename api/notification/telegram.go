@@ -0,0 +1,192 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"notice/api/config"
+	"notice/api/topic"
+)
+
+const (
+	// defaultTelegramAPIURL Telegram Bot API 地址
+	defaultTelegramAPIURL = "https://api.telegram.org"
+	// telegramMaxText Telegram 单条消息最大长度
+	telegramMaxText = 4096
+)
+
+// telegramAllowedTags Telegram HTML parse_mode 支持的标签
+var telegramAllowedTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "ins": true,
+	"s": true, "strike": true, "del": true, "a": true, "code": true, "pre": true,
+	"blockquote": true, "tg-spoiler": true,
+}
+
+var (
+	telegramTagPattern = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+	entityPattern      = regexp.MustCompile(`^&(#[0-9]+|#x[0-9a-fA-F]+|[a-zA-Z]+);`)
+)
+
+// TelegramNotifier 通过 Telegram Bot API 发送 HTML 格式通知
+type TelegramNotifier struct {
+	apiURL        string
+	botToken      string
+	defaultChatID string
+	chatIDs       map[string]string
+	client        *http.Client
+}
+
+// NewTelegramNotifier 创建 Telegram 通知渠道
+func NewTelegramNotifier(cfg config.TelegramNotifierConfig) *TelegramNotifier {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+	return &TelegramNotifier{
+		apiURL:        strings.TrimRight(apiURL, "/"),
+		botToken:      cfg.BotToken,
+		defaultChatID: cfg.DefaultChatID,
+		chatIDs:       cfg.ChatIDs,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// chatID 根据消息主题选择目标 chat，优先匹配最具体的配置（如 rsi:btcusdt 优先于 rsi）
+func (t *TelegramNotifier) chatID(source string) string {
+	// 新来源名称的配置优先，其次是旧名称（如 doji）的配置
+	normalized := topic.Normalize(source)
+	for _, name := range append([]string{normalized}, topic.Aliases(normalized)...) {
+		for _, prefix := range topic.Prefixes(name) {
+			if id, ok := t.chatIDs[prefix]; ok && id != "" {
+				return id
+			}
+		}
+	}
+	return t.defaultChatID
+}
+
+func (t *TelegramNotifier) Notify(title, message, source string) error {
+	chatID := t.chatID(source)
+	if chatID == "" {
+		// 该来源未配置 chat，不发送
+		return nil
+	}
+
+	// 在转义和加标签之前截断原始消息，避免截断落在标签或实体中间；预留标题、换行和省略号
+	limit := telegramMaxText - 1
+	if title != "" {
+		limit -= len([]rune(title)) + 1
+	}
+	if r := []rune(message); len(r) > limit {
+		message = string(r[:max(limit, 0)]) + "…"
+	}
+
+	text := sanitizeTelegramHTML(message)
+	if title != "" {
+		text = "<b>" + html.EscapeString(title) + "</b>\n" + text
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.apiURL, t.botToken)
+	resp, err := t.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram status=%d: %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram status=%d: %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
+
+// sanitizeTelegramHTML 将消息转换为 Telegram 可解析的 HTML：
+// 保留支持的标签，<br>/<p> 转为换行，去掉其他标签，并转义多余的 < > &；未闭合的标签在末尾补齐
+func sanitizeTelegramHTML(message string) string {
+	var sb strings.Builder
+	var open []string
+	last := 0
+	for _, m := range telegramTagPattern.FindAllStringSubmatchIndex(message, -1) {
+		sb.WriteString(escapeTelegramText(message[last:m[0]]))
+		last = m[1]
+
+		tag := strings.ToLower(message[m[2]:m[3]])
+		closing := strings.HasPrefix(message[m[0]:m[1]], "</")
+		switch {
+		case telegramAllowedTags[tag]:
+			if closing {
+				if n := len(open); n > 0 && open[n-1] == tag {
+					open = open[:n-1]
+				}
+			} else {
+				open = append(open, tag)
+			}
+			if closing || tag != "a" {
+				// 去掉属性，只保留标签本身
+				if closing {
+					sb.WriteString("</" + tag + ">")
+				} else {
+					sb.WriteString("<" + tag + ">")
+				}
+			} else {
+				sb.WriteString(message[m[0]:m[1]])
+			}
+		case tag == "br" || (tag == "p" && closing):
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(escapeTelegramText(message[last:]))
+	text := strings.TrimSpace(sb.String())
+	for i := len(open) - 1; i >= 0; i-- {
+		text += "</" + open[i] + ">"
+	}
+	return text
+}
+
+// escapeTelegramText 转义文本中的 < > 和未构成实体的 &
+func escapeTelegramText(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '&':
+			if entityPattern.MatchString(text[i:]) {
+				sb.WriteByte(c)
+			} else {
+				sb.WriteString("&amp;")
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"notice/api/config"
+)
+
+func TestTelegramNotifier(t *testing.T) {
+	var gotPath string
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	n := NewTelegramNotifier(config.TelegramNotifierConfig{
+		BotToken:      "123:abc",
+		APIURL:        srv.URL,
+		DefaultChatID: "-100",
+		ChatIDs:       map[string]string{"news": "-200"},
+	})
+
+	message := "<b>比特币突破新高</b>\n<p>现货 ETF 净流入 &amp; 创纪录</p><img src=\"x.png\">\n<i>Mon, 01 Jan 2024</i>"
+	if err := n.Notify("BlockBeats", message, "news"); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if gotPath != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %s", gotPath)
+	}
+	if payload["chat_id"] != "-200" || payload["parse_mode"] != "HTML" {
+		t.Errorf("payload = %v, want chat_id -200 with HTML parse mode", payload)
+	}
+	want := "<b>BlockBeats</b>\n<b>比特币突破新高</b>\n现货 ETF 净流入 &amp; 创纪录\n\n<i>Mon, 01 Jan 2024</i>"
+	if payload["text"] != want {
+		t.Errorf("text = %q, want %q", payload["text"], want)
+	}
+
+	// 未单独配置的来源使用默认 chat
+	if err := n.Notify("RSI", "BTCUSDT 4h RSI<30", "rsi"); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if payload["chat_id"] != "-100" || payload["text"] != "<b>RSI</b>\nBTCUSDT 4h RSI&lt;30" {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+func TestTelegramChatIDLegacySource(t *testing.T) {
+	n := NewTelegramNotifier(config.TelegramNotifierConfig{DefaultChatID: "-100", ChatIDs: map[string]string{"doji": "-300"}})
+	if got := n.chatID("pattern:btcusdt:4h:hammer"); got != "-300" {
+		t.Errorf("chatID() = %s, want the legacy doji chat -300", got)
+	}
+	n.chatIDs["pattern"] = "-400"
+	if got := n.chatID("pattern:btcusdt:4h:hammer"); got != "-400" {
+		t.Errorf("chatID() = %s, want the pattern chat -400", got)
+	}
+}
+
+func TestTelegramNotifierTruncate(t *testing.T) {
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	n := NewTelegramNotifier(config.TelegramNotifierConfig{APIURL: srv.URL, DefaultChatID: "1"})
+	// 截断点落在 <b> 标签内，且前面的 & 转义后会变长
+	message := strings.Repeat("&", 4000) + "<b>" + strings.Repeat("x", 200) + "</b>"
+	if err := n.Notify("Title", message, "rsi"); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	text := payload["text"].(string)
+	if !strings.HasPrefix(text, "<b>Title</b>\n&amp;") || !strings.HasSuffix(text, "…</b>") {
+		t.Errorf("text should keep escaped entities and close the cut tag, got ...%q", text[len(text)-20:])
+	}
+	visible := strings.NewReplacer("<b>", "", "</b>", "", "&amp;", "&").Replace(text)
+	if got := utf8.RuneCountInString(visible); got > telegramMaxText {
+		t.Errorf("visible length = %d, want <= %d", got, telegramMaxText)
+	}
+}
+
+func TestTelegramNotifierAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: can't parse entities"}`))
+	}))
+	defer srv.Close()
+
+	n := NewTelegramNotifier(config.TelegramNotifierConfig{APIURL: srv.URL, DefaultChatID: "1"})
+	if err := n.Notify("t", "m", "rsi"); err == nil {
+		t.Error("Notify() should return the Bot API error")
+	}
+}
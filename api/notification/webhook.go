@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// discordMaxContent Discord 单条消息最大长度
+const discordMaxContent = 2000
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// plainText 去掉消息中的 HTML 标签（BlockBeats 新闻等消息带有 <b>/<i> 标签）
+func plainText(message string) string {
+	return html.UnescapeString(htmlTagPattern.ReplaceAllString(message, ""))
+}
+
+// postJSON 以 JSON 格式 POST 到 Webhook 地址
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook status=%d body=%s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
+// DiscordNotifier 通过 Discord Webhook 发送通知
+type DiscordNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewDiscordNotifier 创建 Discord 通知渠道
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	return &DiscordNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (d *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+func (d *DiscordNotifier) Notify(title, message, source string) error {
+	content := fmt.Sprintf("**%s**\n%s", title, plainText(message))
+	if r := []rune(content); len(r) > discordMaxContent {
+		content = string(r[:discordMaxContent])
+	}
+	return postJSON(d.client, d.webhookURL, map[string]string{"content": content})
+}
+
+// SlackNotifier 通过 Slack Incoming Webhook 发送通知
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier 创建 Slack 通知渠道
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (s *SlackNotifier) Notify(title, message, source string) error {
+	text := fmt.Sprintf("*%s*\n%s", title, plainText(message))
+	return postJSON(s.client, s.webhookURL, map[string]string{"text": text})
+}
@@ -0,0 +1,331 @@
+package rsi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/model"
+	"notice/api/notification"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// 信号类型，RSI 信号与 model.RSISignal.SignalType 一致
+const (
+	signalOverbought    = "overbought"
+	signalOversold      = "oversold"
+	signalBullish       = "bullish"        // MACD 柱由负转正
+	signalBearish       = "bearish"        // MACD 柱由正转负
+	signalGoldenCross   = "golden_cross"   // 快线上穿慢线
+	signalDeathCross    = "death_cross"    // 快线下穿慢线
+	signalUpperBreakout = "upper_breakout" // 收盘价突破布林上轨
+	signalLowerBreakout = "lower_breakout" // 收盘价跌破布林下轨
+)
+
+// condition 告警条件，输入指标读数，触发时返回信号类型，否则返回空字符串
+type condition interface {
+	update(v float64) string
+}
+
+// thresholds 超买超卖阈值
+type thresholds struct {
+	overbought float64
+	oversold   float64
+	hysteresis float64
+}
+
+var (
+	alertMu  sync.RWMutex
+	alertCfg config.RSIAlertConfig
+	signalDB *gorm.DB
+
+	// sendAlert K线收盘告警（指标、共振、形态、成交量）的发送函数，测试中可替换
+	sendAlert = notification.SendNotificationWithTitle
+)
+
+// SetAlertConfig 设置 RSI 告警阈值配置，应在启动 RSI 任务前调用
+func SetAlertConfig(cfg config.RSIAlertConfig) {
+	alertMu.Lock()
+	defer alertMu.Unlock()
+	alertCfg = cfg
+}
+
+// SetSignalStore 设置 RSI 信号的持久化数据库，未设置时告警只推送不入库
+func SetSignalStore(db *gorm.DB) {
+	alertMu.Lock()
+	defer alertMu.Unlock()
+	signalDB = db
+}
+
+// thresholdsFor 返回交易对/周期对应的阈值，规则中未设置的字段使用全局默认值
+func thresholdsFor(symbol, interval string) thresholds {
+	alertMu.RLock()
+	defer alertMu.RUnlock()
+
+	th := thresholds{overbought: 70, oversold: 30, hysteresis: 2}
+	if alertCfg.Overbought > 0 {
+		th.overbought = alertCfg.Overbought
+	}
+	if alertCfg.Oversold > 0 {
+		th.oversold = alertCfg.Oversold
+	}
+	if alertCfg.Hysteresis > 0 {
+		th.hysteresis = alertCfg.Hysteresis
+	}
+
+	for _, rule := range alertCfg.Rules {
+		if rule.Symbol != "" && !strings.EqualFold(rule.Symbol, symbol) {
+			continue
+		}
+		if rule.Interval != "" && rule.Interval != interval {
+			continue
+		}
+		if rule.Overbought > 0 {
+			th.overbought = rule.Overbought
+		}
+		if rule.Oversold > 0 {
+			th.oversold = rule.Oversold
+		}
+		if rule.Hysteresis > 0 {
+			th.hysteresis = rule.Hysteresis
+		}
+	}
+	return th
+}
+
+// 指标所处区间
+type zone int
+
+const (
+	zoneNeutral zone = iota
+	zoneOversold
+	zoneOverbought
+)
+
+// levelAlert 阈值穿越检测（带回差，避免在阈值附近反复告警）
+type levelAlert struct {
+	th          thresholds
+	above       string // 进入上方区间时的信号类型
+	below       string // 进入下方区间时的信号类型
+	zone        zone
+	initialized bool
+}
+
+func newLevelAlert(th thresholds) *levelAlert {
+	return &levelAlert{th: th, above: signalOverbought, below: signalOversold}
+}
+
+// update 输入最新指标值，进入超买/超卖区间时返回对应信号类型，否则返回空字符串。
+// 第一个值只用于确定初始区间，不产生信号。
+func (a *levelAlert) update(v float64) string {
+	next := a.zone
+	switch a.zone {
+	case zoneNeutral:
+		if v >= a.th.overbought {
+			next = zoneOverbought
+		} else if v <= a.th.oversold {
+			next = zoneOversold
+		}
+	case zoneOverbought:
+		if v <= a.th.oversold {
+			next = zoneOversold
+		} else if v < a.th.overbought-a.th.hysteresis {
+			next = zoneNeutral
+		}
+	case zoneOversold:
+		if v >= a.th.overbought {
+			next = zoneOverbought
+		} else if v > a.th.oversold+a.th.hysteresis {
+			next = zoneNeutral
+		}
+	}
+
+	entered := next != a.zone
+	a.zone = next
+	if !a.initialized {
+		a.initialized = true
+		return ""
+	}
+	if !entered {
+		return ""
+	}
+	switch next {
+	case zoneOverbought:
+		return a.above
+	case zoneOversold:
+		return a.below
+	}
+	return ""
+}
+
+// crossAlert 零轴穿越检测：读数由负转正或由正转负时触发，等于0时保持原方向
+type crossAlert struct {
+	up   string // 由负转正时的信号类型
+	down string // 由正转负时的信号类型
+	sign int
+}
+
+func newCrossAlert(up, down string) *crossAlert {
+	return &crossAlert{up: up, down: down}
+}
+
+// update 第一个非零值只用于确定初始方向，不产生信号
+func (a *crossAlert) update(v float64) string {
+	sign := 0
+	if v > 0 {
+		sign = 1
+	} else if v < 0 {
+		sign = -1
+	}
+	if sign == 0 || sign == a.sign {
+		return ""
+	}
+	prev := a.sign
+	a.sign = sign
+	if prev == 0 {
+		return ""
+	}
+	if sign > 0 {
+		return a.up
+	}
+	return a.down
+}
+
+// alertMessage 告警推送内容
+type alertMessage struct {
+	title string
+	body  string
+	topic string
+}
+
+// sendIndicatorAlert 推送指标告警，RSI 信号同时记录到 rsi_signals 表
+func sendIndicatorAlert(symbol, interval string, sig indicatorSignal) {
+	c := sig.candle
+	closeTime := time.UnixMilli(c.CloseTime)
+	msg := indicatorAlertMessage(symbol, interval, sig)
+
+	if sig.indicator == indicatorRSI && confluence.suppresses(symbol, interval) {
+		// 由多周期共振告警替代，只记录信号
+		logx.Infof("RSI %s %s %s covered by confluence rule, push skipped", strings.ToUpper(symbol), interval, sig.signal)
+		saveRSISignal(symbol, interval, sig.signal, sig.value, c.Close, c.Volume, closeTime, false)
+		return
+	}
+
+	logx.Infof("Indicator alert: %s", strings.ReplaceAll(msg.body, "\n", " "))
+	err := sendAlert(msg.body, msg.title, msg.topic)
+	if err != nil {
+		logx.Errorf("Failed to send %s alert: %v", sig.indicator, err)
+	}
+	if sig.indicator == indicatorRSI {
+		saveRSISignal(symbol, interval, sig.signal, sig.value, c.Close, c.Volume, closeTime, err == nil)
+	}
+}
+
+// indicatorAlertMessage 生成指标告警的标题、正文和主题，实时监控和回测共用
+func indicatorAlertMessage(symbol, interval string, sig indicatorSignal) alertMessage {
+	if sig.div != nil {
+		return divergenceAlertMessage(symbol, interval, sig)
+	}
+	if sig.indicator == indicatorRSI {
+		return rsiAlertMessage(symbol, interval, sig)
+	}
+
+	c := sig.candle
+	sym := strings.ToUpper(symbol)
+	var title, desc string
+	switch sig.signal {
+	case signalBullish:
+		title, desc = "MACD看涨", "柱由负转正"
+	case signalBearish:
+		title, desc = "MACD看跌", "柱由正转负"
+	case signalGoldenCross:
+		title, desc = "EMA金叉", "快线上穿慢线"
+	case signalDeathCross:
+		title, desc = "EMA死叉", "快线下穿慢线"
+	case signalUpperBreakout:
+		title, desc = "布林上轨突破", "收盘价突破上轨"
+	case signalLowerBreakout:
+		title, desc = "布林下轨跌破", "收盘价跌破下轨"
+	default:
+		title, desc = sig.name, sig.signal
+	}
+	return alertMessage{
+		title: fmt.Sprintf("%s %s %s", title, sym, interval),
+		body: fmt.Sprintf("%s %s %s %s (%.4f)\nclose=%.2f @ %s",
+			sym, interval, sig.name, desc, sig.value, c.Close, time.UnixMilli(c.CloseTime).Format(time.RFC3339)),
+		topic: topic.Of(sig.indicator, symbol, interval),
+	}
+}
+
+// divergenceAlertMessage 背离告警，包含两个摆动点的价格和 RSI
+func divergenceAlertMessage(symbol, interval string, sig indicatorSignal) alertMessage {
+	d := sig.div
+	sym := strings.ToUpper(symbol)
+
+	var title, point string
+	switch d.kind {
+	case signalBullishDivergence:
+		title, point = "RSI看涨背离", "低点"
+	case signalHiddenBullishDivergence:
+		title, point = "RSI隐藏看涨背离", "低点"
+	case signalBearishDivergence:
+		title, point = "RSI看跌背离", "高点"
+	case signalHiddenBearishDivergence:
+		title, point = "RSI隐藏看跌背离", "高点"
+	}
+	return alertMessage{
+		title: fmt.Sprintf("%s %s %s", title, sym, interval),
+		body: fmt.Sprintf("%s %s %s\n价格%s: %.2f → %.2f\nRSI%s: %.2f → %.2f\n%s → %s",
+			sym, interval, sig.name,
+			point, d.prev.price, d.curr.price,
+			point, d.prev.rsi, d.curr.rsi,
+			time.UnixMilli(d.prev.time).Format(time.RFC3339), time.UnixMilli(d.curr.time).Format(time.RFC3339)),
+		topic: topic.Of(indicatorDivergence, symbol, interval),
+	}
+}
+
+// rsiAlertMessage RSI 超买/超卖穿越告警
+func rsiAlertMessage(symbol, interval string, sig indicatorSignal) alertMessage {
+	sym := strings.ToUpper(symbol)
+	c := sig.candle
+	ts := time.UnixMilli(c.CloseTime).Format(time.RFC3339)
+
+	msg := alertMessage{topic: topic.Of("rsi", symbol, interval)}
+	if sig.signal == signalOverbought {
+		msg.title = fmt.Sprintf("RSI超买 %s %s", sym, interval)
+		msg.body = fmt.Sprintf("%s %s %s=%.2f 上穿 %.0f (超买)\nclose=%.2f @ %s",
+			sym, interval, sig.name, sig.value, sig.th.overbought, c.Close, ts)
+	} else {
+		msg.title = fmt.Sprintf("RSI超卖 %s %s", sym, interval)
+		msg.body = fmt.Sprintf("%s %s %s=%.2f 下穿 %.0f (超卖)\nclose=%.2f @ %s",
+			sym, interval, sig.name, sig.value, sig.th.oversold, c.Close, ts)
+	}
+	return msg
+}
+
+// saveRSISignal 记录 RSI 信号到 rsi_signals 表，未设置数据库时跳过
+func saveRSISignal(symbol, interval, signalType string, value, closePrice, volume float64, closeTime time.Time, sent bool) {
+	alertMu.RLock()
+	db := signalDB
+	alertMu.RUnlock()
+	if db != nil {
+		signal := model.RSISignal{
+			Symbol:     strings.ToUpper(symbol),
+			Interval:   interval,
+			RSIValue:   value,
+			SignalType: signalType,
+			Price:      closePrice,
+			Volume:     volume,
+			SignalTime: closeTime,
+			IsSent:     sent,
+		}
+		if err := db.Create(&signal).Error; err != nil {
+			logx.Errorf("Failed to save RSI signal: %v", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rsi
+
+import (
+	"testing"
+
+	"notice/api/config"
+)
+
+func TestLevelAlertHysteresis(t *testing.T) {
+	a := newLevelAlert(thresholds{overbought: 70, oversold: 30, hysteresis: 2})
+
+	steps := []struct {
+		value float64
+		want  string
+	}{
+		{50, ""},               // 初始化
+		{71, signalOverbought}, // 上穿 70
+		{72, ""},               // 仍在超买区
+		{69, ""},               // 回落但未超过回差
+		{71, ""},               // 不重复告警
+		{67, ""},               // 回落到 68 以下，离开超买区
+		{70, signalOverbought}, // 再次上穿
+		{29, signalOversold},   // 直接进入超卖区
+		{31, ""},
+		{30, ""},
+		{33, ""},
+		{30, signalOversold},
+	}
+	for i, s := range steps {
+		if got := a.update(s.value); got != s.want {
+			t.Fatalf("step %d: update(%v) = %q, want %q", i, s.value, got, s.want)
+		}
+	}
+}
+
+func TestLevelAlertStartsInZone(t *testing.T) {
+	a := newLevelAlert(thresholds{overbought: 70, oversold: 30, hysteresis: 2})
+	if got := a.update(80); got != "" {
+		t.Fatalf("first value should not alert, got %q", got)
+	}
+	if got := a.update(75); got != "" {
+		t.Fatalf("still overbought, got %q", got)
+	}
+}
+
+func TestThresholdsForRules(t *testing.T) {
+	defer SetAlertConfig(config.RSIAlertConfig{})
+
+	SetAlertConfig(config.RSIAlertConfig{
+		Oversold: 25,
+		Rules: []config.RSIAlertRule{
+			{Symbol: "BTCUSDT", Interval: "1d", Overbought: 80},
+		},
+	})
+
+	th := thresholdsFor("btcusdt", "1d")
+	if th.overbought != 80 || th.oversold != 25 || th.hysteresis != 2 {
+		t.Fatalf("unexpected thresholds for btcusdt 1d: %+v", th)
+	}
+	th = thresholdsFor("btcusdt", "4h")
+	if th.overbought != 70 || th.oversold != 25 {
+		t.Fatalf("unexpected thresholds for btcusdt 4h: %+v", th)
+	}
+}
@@ -0,0 +1,192 @@
+package rsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"notice/api/config"
+)
+
+// DefaultBacktestHorizons 默认统计信号后 1/5/10/20 根K线的收益
+var DefaultBacktestHorizons = []int{1, 5, 10, 20}
+
+// BacktestAlert 回测中会推送的一条告警
+type BacktestAlert struct {
+	Time    time.Time
+	Source  string // rsi/macd/ema_cross/bollinger/divergence/pattern/volume
+	Signal  string
+	Title   string
+	Body    string
+	Topic   string
+	Close   float64
+	Returns map[int]float64 // 信号后 N 根K线的收盘价收益率（%），K线不足时缺省
+}
+
+// BacktestSummary 同一来源和信号类型的统计
+type BacktestSummary struct {
+	Source  string
+	Signal  string
+	Count   int
+	AvgRet  map[int]float64 // 平均收益率（%）
+	WinRate map[int]float64 // 收益为正的比例（%）
+	Samples map[int]int     // 参与统计的信号数
+}
+
+// BacktestReport 回测结果
+type BacktestReport struct {
+	Symbol   string
+	Interval string
+	Candles  int
+	From     time.Time
+	To       time.Time
+	Horizons []int
+	Alerts   []BacktestAlert
+	Summary  []BacktestSummary
+
+	Suppressed int // 被多周期共振规则覆盖、实时监控不会单独推送的 RSI 告警数
+}
+
+// FetchKlines 返回最近 limit 根历史K线（包含未收盘的当前K线）：优先读取K线存储，缺口和最新的K线从 REST 补齐，
+// 超过单次上限时自动分页
+func FetchKlines(symbol, interval string, limit int) ([]Candle, error) {
+	return fetchHistoricalKlines(symbol, interval, limit)
+}
+
+// Backtest 用与实时监控相同的指标、告警条件和形态检测处理历史K线，
+// 只记录会推送的告警，不发送通知也不写数据库。多周期共振依赖多个周期的实时读数，不参与回测，
+// 但与实时监控一样，被共振规则覆盖的周期不计入单独的 RSI 告警
+func Backtest(w config.WatchConfig, interval string, candles []Candle, horizons []int) (*BacktestReport, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles")
+	}
+	period := w.Period
+	if period <= 0 {
+		period = defaultPeriod
+	}
+	if len(horizons) == 0 {
+		horizons = DefaultBacktestHorizons
+	}
+
+	set, err := newIndicatorSet(w.Indicators, period, watchThresholds(w, interval))
+	if err != nil {
+		return nil, err
+	}
+	patterns := newPatternDetector(interval)
+	volume := newVolumeDetector(interval)
+
+	sorted := make([]Candle, len(candles))
+	copy(sorted, candles)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CloseTime < sorted[j].CloseTime })
+
+	report := &BacktestReport{
+		Symbol:   strings.ToUpper(w.Symbol),
+		Interval: interval,
+		From:     time.UnixMilli(sorted[0].OpenTime),
+		To:       time.UnixMilli(sorted[len(sorted)-1].CloseTime),
+		Horizons: horizons,
+	}
+
+	var lastTs int64
+	for i, c := range sorted {
+		// 与实时监控一样跳过重复的K线
+		if i > 0 && c.CloseTime <= lastTs {
+			continue
+		}
+		lastTs = c.CloseTime
+		report.Candles++
+
+		for _, sig := range set.update(c) {
+			if sig.indicator == indicatorRSI && confluence.suppresses(w.Symbol, interval) {
+				report.Suppressed++
+				continue
+			}
+			msg := indicatorAlertMessage(w.Symbol, interval, sig)
+			report.Alerts = append(report.Alerts, newBacktestAlert(sorted, i, sig.indicator, sig.signal, msg, horizons))
+		}
+		if patterns != nil {
+			for _, m := range patterns.update(c) {
+				msg := patternAlertMessage(w.Symbol, interval, m)
+				report.Alerts = append(report.Alerts, newBacktestAlert(sorted, i, "pattern", m.name, msg, horizons))
+			}
+		}
+		if volume != nil {
+			if spike, ok := volume.update(c); ok {
+				msg := volumeAlertMessage(w.Symbol, interval, spike)
+				report.Alerts = append(report.Alerts, newBacktestAlert(sorted, i, "volume", spike.side(), msg, horizons))
+			}
+		}
+	}
+	report.Summary = summarizeBacktest(report.Alerts, horizons)
+	return report, nil
+}
+
+// newBacktestAlert 记录告警并计算信号后各周期的收益
+func newBacktestAlert(candles []Candle, i int, source, signal string, msg alertMessage, horizons []int) BacktestAlert {
+	c := candles[i]
+	a := BacktestAlert{
+		Time:    time.UnixMilli(c.CloseTime),
+		Source:  source,
+		Signal:  signal,
+		Title:   msg.title,
+		Body:    msg.body,
+		Topic:   msg.topic,
+		Close:   c.Close,
+		Returns: make(map[int]float64, len(horizons)),
+	}
+	for _, h := range horizons {
+		if h <= 0 || i+h >= len(candles) || c.Close == 0 {
+			continue
+		}
+		a.Returns[h] = (candles[i+h].Close - c.Close) / c.Close * 100
+	}
+	return a
+}
+
+// summarizeBacktest 按来源和信号类型汇总，结果按来源、信号排序
+func summarizeBacktest(alerts []BacktestAlert, horizons []int) []BacktestSummary {
+	index := make(map[string]int)
+	var summary []BacktestSummary
+	for _, a := range alerts {
+		key := a.Source + "|" + a.Signal
+		idx, ok := index[key]
+		if !ok {
+			idx = len(summary)
+			index[key] = idx
+			summary = append(summary, BacktestSummary{
+				Source:  a.Source,
+				Signal:  a.Signal,
+				AvgRet:  make(map[int]float64),
+				WinRate: make(map[int]float64),
+				Samples: make(map[int]int),
+			})
+		}
+		s := &summary[idx]
+		s.Count++
+		for h, r := range a.Returns {
+			s.AvgRet[h] += r
+			s.Samples[h]++
+			if r > 0 {
+				s.WinRate[h]++
+			}
+		}
+	}
+
+	for i := range summary {
+		s := &summary[i]
+		for _, h := range horizons {
+			if n := s.Samples[h]; n > 0 {
+				s.AvgRet[h] /= float64(n)
+				s.WinRate[h] = s.WinRate[h] / float64(n) * 100
+			}
+		}
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Source != summary[j].Source {
+			return summary[i].Source < summary[j].Source
+		}
+		return summary[i].Signal < summary[j].Signal
+	})
+	return summary
+}
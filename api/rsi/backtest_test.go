@@ -0,0 +1,79 @@
+package rsi
+
+import (
+	"math"
+	"testing"
+
+	"notice/api/config"
+)
+
+func TestBacktestAlertsAndReturns(t *testing.T) {
+	// 先横盘让 RSI 从中性区间开始，再下跌后反弹
+	values := append([]float64{100, 101, 100, 101, 100, 101, 100, 101}, vShape(15)...)
+	candles := closes(values...)
+	// 乱序和重复的K线按收盘时间整理
+	input := append([]Candle{candles[len(candles)-1]}, candles...)
+
+	report, err := Backtest(config.WatchConfig{Symbol: "btcusdt", Period: 5}, "1h", input, []int{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Candles != len(candles) || report.Symbol != "BTCUSDT" {
+		t.Fatalf("unexpected report header: %+v", report)
+	}
+	if len(report.Alerts) != 2 {
+		t.Fatalf("expected oversold then overbought, got %+v", report.Alerts)
+	}
+
+	low, high := report.Alerts[0], report.Alerts[1]
+	if low.Signal != signalOversold || high.Signal != signalOverbought || low.Source != indicatorRSI {
+		t.Fatalf("unexpected signals: %s %s", low.Signal, high.Signal)
+	}
+	if low.Title != "RSI超卖 BTCUSDT 1h" || low.Topic != "rsi:btcusdt:1h" {
+		t.Fatalf("alert should use the live message format, got %q %q", low.Title, low.Topic)
+	}
+
+	// 收益按信号后第 N 根K线的收盘价计算
+	i := int(low.Time.UnixMilli()/60000) - 1
+	want := (candles[i+3].Close - candles[i].Close) / candles[i].Close * 100
+	if math.Abs(low.Returns[3]-want) > 1e-9 {
+		t.Fatalf("return +3 = %v, want %v", low.Returns[3], want)
+	}
+
+	if len(report.Summary) != 2 || report.Summary[0].Signal != signalOverbought || report.Summary[0].Count != 1 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+}
+
+func TestBacktestConfluenceSuppression(t *testing.T) {
+	SetConfluenceRules([]config.ConfluenceRule{{Intervals: []string{"1h", "4h"}}})
+	t.Cleanup(func() { SetConfluenceRules(nil) })
+
+	values := append([]float64{100, 101, 100, 101, 100, 101, 100, 101}, vShape(15)...)
+	report, err := Backtest(config.WatchConfig{Symbol: "btcusdt", Period: 5}, "1h", closes(values...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Alerts) != 0 || report.Suppressed != 2 {
+		t.Fatalf("RSI alerts covered by confluence should be suppressed, got %d alerts, %d suppressed", len(report.Alerts), report.Suppressed)
+	}
+
+	// 未被规则覆盖的周期照常记录
+	report, err = Backtest(config.WatchConfig{Symbol: "btcusdt", Period: 5}, "2h", closes(values...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Alerts) != 2 || report.Suppressed != 0 {
+		t.Fatalf("uncovered interval should keep its alerts, got %d alerts, %d suppressed", len(report.Alerts), report.Suppressed)
+	}
+}
+
+func TestBacktestInvalidInput(t *testing.T) {
+	if _, err := Backtest(config.WatchConfig{Symbol: "btcusdt"}, "1h", nil, nil); err == nil {
+		t.Fatal("expected error without candles")
+	}
+	w := config.WatchConfig{Symbol: "btcusdt", Indicators: []config.IndicatorConfig{{Type: "vwap"}}}
+	if _, err := Backtest(w, "1h", closes(1, 2, 3), nil); err == nil {
+		t.Fatal("expected error for unknown indicator")
+	}
+}
@@ -2,9 +2,7 @@ package rsi
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"strconv"
@@ -12,9 +10,9 @@ import (
 	"time"
 
 	"notice/api/notification"
+	"notice/api/topic"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -101,160 +99,215 @@ func (r *rsiCalc) compute() float64 {
 	return 100 - 100/(1+rs)
 }
 
-// ---- REST 历史K线拉取（USDT 永续：fapi/v1/klines） ----
-type histCandle struct {
-	close     float64
-	closeTime int64
+// StartBinanceRSI connects to Binance futures kline stream, computes RSI on candle close, and broadcasts.
+// 监控注册到 watcher 注册表中，阻塞直到该监控被停止
+func StartBinanceRSI(symbol, interval string, period int) {
+	w, err := GetWatchers().add(symbol, interval, period, thresholdsFor(symbol, interval), nil, 0)
+	if err != nil {
+		logx.Errorf("Failed to start RSI watcher: %v", err)
+		return
+	}
+	<-w.done
 }
 
-func fetchHistoricalKlines(symbol, interval string, limit int) ([]histCandle, error) {
-	// Binance Futures REST (USDT-M): https://fapi.binance.com/fapi/v1/klines
-	// Response: [[openTime, open, high, low, close, volume, closeTime, ...], ...]
-	sym := strings.ToUpper(symbol)
-	if limit <= 0 || limit > 1500 {
-		limit = 1000
-	}
-	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?symbol=%s&interval=%s&limit=%d", sym, interval, limit)
+// runWatcher 单个交易对/周期的 RSI 监控：订阅共享的 K 线组合流，ctx 取消后退出。
+// 组合流每次（重新）连接后重新拉取历史完成预热，补上断线期间的K线
+func runWatcher(ctx context.Context, w *watcher) {
+	events := make(chan binanceKline, 64)
+	states := make(chan bool, 8)
+	unsubscribe := getKlineStream().subscribe(w.symbol, w.interval, streamConsumer{
+		onKline: func(ev binanceKline) {
+			// only act on candle close to avoid noise
+			if !ev.K.IsClosed {
+				return
+			}
+			select {
+			case events <- ev:
+			default:
+				logx.Errorf("RSI watcher %s %s is lagging, kline dropped", strings.ToUpper(w.symbol), w.interval)
+			}
+		},
+		onState: func(connected bool) {
+			select {
+			case states <- connected:
+			default:
+			}
+		},
+	})
+	defer unsubscribe()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
+	var st *watchState
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case connected := <-states:
+			w.setConnected(connected)
+			if !connected {
+				continue
+			}
+			st = w.warmup()
+			// 若 warmup 期间无法获得足够K线，WS 收到的后续 close 会逐步完成初始化
+			msg := fmt.Sprintf("[RSI] connected %s %s period=%d", strings.ToUpper(w.symbol), w.interval, w.period)
+			logx.Infof("RSI connection signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
+			notification.SendNotification(msg, topic.Of("rsi", w.symbol, w.interval))
+		case ev := <-events:
+			if st == nil {
+				st = w.newState()
+			}
+			w.handleClose(st, ev.candle())
+		}
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("binance klines status=%d body=%s", resp.StatusCode, string(b))
+}
+
+// watchState watcher 的指标计算状态，每次预热时重建
+type watchState struct {
+	set      *indicatorSet
+	patterns *patternDetector // 该周期没有适用的形态规则时为 nil
+	volume   *volumeDetector  // 成交量异动检测关闭或该周期不适用时为 nil
+	lastTs   int64
+}
+
+func (w *watcher) newState() *watchState {
+	// 指标配置在 watcher 创建时已校验
+	set, _ := newIndicatorSet(w.indicators, w.period, w.th)
+	return &watchState{set: set, patterns: newPatternDetector(w.interval), volume: newVolumeDetector(w.interval)}
+}
+
+// warmup 拉取历史K线完成指标预热；预热期间只更新告警状态不发送告警，
+// 预热后的指标和告警状态直接用于实时数据
+func (w *watcher) warmup() *watchState {
+	symbol, interval := w.symbol, w.interval
+	st := w.newState()
+
+	limit := w.lookback
+	if limit <= 0 {
+		_, limit, _ = klineSettings()
 	}
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return nil, err
+	hist, err := fetchHistoricalKlines(symbol, interval, limit)
+	if err != nil {
+		w.setError(err)
+		msg := fmt.Sprintf("[RSI] warmup error: %v", err)
+		logx.Errorf("RSI warmup error signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
+		notification.SendNotification(msg, topic.Of("rsi", symbol, interval))
+		return st
 	}
-	result := make([]histCandle, 0, len(raw))
-	for _, it := range raw {
-		if len(it) < 7 {
+
+	now := time.Now().UnixMilli()
+	for _, c := range hist {
+		// 跳过尚未收盘的当前K线
+		if c.CloseTime >= now {
 			continue
 		}
-		// close at index 4, closeTime at index 6
-		closeStr, _ := it[4].(string)
-		closeTime, _ := it[6].(float64)
-		result = append(result, histCandle{
-			close:     toFloat(closeStr),
-			closeTime: int64(closeTime),
-		})
+		st.set.update(c)
+		if st.patterns != nil {
+			st.patterns.update(c)
+		}
+		if st.volume != nil {
+			st.volume.update(c)
+		}
+		st.lastTs = c.CloseTime
+	}
+	if v, _, ok := st.set.rsi(); ok {
+		confluence.update(symbol, interval, v, true)
+	}
+	if st.set.ready() {
+		w.setReading(st.set, time.UnixMilli(st.lastTs))
+		ts := time.UnixMilli(st.lastTs).Format(time.RFC3339)
+		msg := fmt.Sprintf("[RSI] warmup done %s %s %s @ %s", strings.ToUpper(symbol), interval, formatValues(st.set.values()), ts)
+		logx.Infof("RSI warmup signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
+		notification.SendNotification(msg, topic.Of("rsi", symbol, interval))
+	} else {
+		msg := fmt.Sprintf("[RSI] warmup pending %s %s need more candles", strings.ToUpper(symbol), interval)
+		logx.Infof("RSI warmup pending signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
+		notification.SendNotification(msg, topic.Of("rsi", symbol, interval))
 	}
-	return result, nil
+	return st
 }
 
-// StartBinanceRSI connects to Binance futures kline stream, computes RSI on candle close, and broadcasts
-func StartBinanceRSI(symbol, interval string, period int) {
-	sym := strings.ToLower(symbol)
-	url := fmt.Sprintf("wss://fstream.binance.com/ws/%s@kline_%s", sym, interval)
-	backoff := time.Second
-	if backoff < time.Second {
-		backoff = time.Second
+// handleClose 处理一根收盘K线：更新指标、发布读数，并检测告警条件、K线形态和成交量异动
+func (w *watcher) handleClose(st *watchState, c Candle) {
+	symbol, interval := w.symbol, w.interval
+	if c.CloseTime <= st.lastTs {
+		return
+	}
+	// 与上一根K线之间有缺口时先通过 REST 补齐，缺失的K线只更新指标不告警
+	if st.lastTs > 0 && c.OpenTime > st.lastTs+1 {
+		w.backfill(st, st.lastTs+1, c.OpenTime-1)
+	}
+	st.lastTs = c.CloseTime
+	saveCandles(symbol, interval, []Candle{c})
+	closePrice := c.Close
+	signals := st.set.update(c)
+	closeTime := time.UnixMilli(c.CloseTime)
+
+	// 每根K线收盘的指标读数只通过 SSE/API 提供，不再推送
+	if values := st.set.values(); len(values) > 0 {
+		w.setReading(st.set, closeTime)
+		rsiValue, period, _ := st.set.rsi()
+		publishReading(Reading{
+			Symbol:     strings.ToUpper(symbol),
+			Interval:   interval,
+			Period:     period,
+			Close:      closePrice,
+			RSI:        rsiValue,
+			Indicators: values,
+			CloseTime:  closeTime,
+		})
 	}
 
-	for {
-		// 先拉取历史，完成 RSI 预热
-		if hist, err := fetchHistoricalKlines(symbol, interval, 1000); err == nil {
-			rsi := newRSI(period)
-			var lastVal float64
-			var ready bool
-			var lastTs int64
-			for _, c := range hist {
-				lastVal, ready = rsi.add(c.close)
-				lastTs = c.closeTime
-			}
-			if ready {
-				ts := time.UnixMilli(lastTs).Format(time.RFC3339)
-				msg := fmt.Sprintf("[RSI] warmup done %s %s RSI(%d)=%.2f @ %s", strings.ToUpper(symbol), interval, period, lastVal, ts)
-				logx.Infof("RSI warmup signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-				notification.SendNotification(msg, "rsi")
-			} else {
-				msg := fmt.Sprintf("[RSI] warmup pending %s %s need more candles", strings.ToUpper(symbol), interval)
-				logx.Infof("RSI warmup pending signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-				notification.SendNotification(msg, "rsi")
-			}
-		} else {
-			msg := fmt.Sprintf("[RSI] warmup error: %v", err)
-			logx.Errorf("RSI warmup error signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-			notification.SendNotification(msg, "rsi")
-		}
+	// 只在满足告警条件（RSI 穿越阈值、MACD 柱翻转、EMA 交叉、布林带突破）时推送
+	for _, sig := range signals {
+		sendIndicatorAlert(symbol, interval, sig)
+	}
 
-		dialer := websocket.Dialer{
-			HandshakeTimeout:  10 * time.Second,
-			EnableCompression: true,
-		}
-		conn, _, err := dialer.Dial(url, nil)
-		if err != nil {
-			// broker.Broadcast(fmt.Sprintf("[RSI] connect error: %v", err))
-			time.Sleep(backoff)
-			backoff = minDuration(backoff*2, 60*time.Second)
-			continue
+	// 多周期共振：满足规则时合并为一条告警
+	if v, _, ok := st.set.rsi(); ok {
+		for _, alert := range confluence.update(symbol, interval, v, false) {
+			sendConfluenceAlert(alert, closePrice, closeTime)
 		}
+	}
 
-		rsi := newRSI(period)
-		// 若 warmup 期间无法获得足够K线，WS 收到的后续 close 会逐步完成初始化
-		msg := fmt.Sprintf("[RSI] connected %s %s period=%d", strings.ToUpper(symbol), interval, period)
-		logx.Infof("RSI connection signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-		notification.SendNotification(msg, "rsi")
-		// reset backoff on successful connect
-		backoff = time.Second
-
-		for {
-			_, data, err := conn.ReadMessage()
-			if err != nil {
-				// broker.Broadcast(fmt.Sprintf("[RSI] read error: %v", err))
-				_ = conn.Close()
-				break
-			}
+	// K 线形态（锤子线、吞没、十字星等），按配置的周期检测
+	if st.patterns != nil {
+		for _, m := range st.patterns.update(c) {
+			sendPatternAlert(symbol, interval, m)
+		}
+	}
 
-			var ev binanceKline
-			if err := json.Unmarshal(data, &ev); err != nil {
-				continue
-			}
-			// only act on candle close to avoid noise
-			if !ev.K.IsClosed {
-				continue
-			}
-			openPrice := toFloat(ev.K.Open)
-			closePrice := toFloat(ev.K.Close)
-			value, ready := rsi.add(closePrice)
-			if !ready {
-				continue
-			}
-			ts := time.UnixMilli(ev.K.CloseTime).Format(time.RFC3339)
-			msg := fmt.Sprintf("%s %s close=%.2f RSI(%d)=%.2f @ %s", strings.ToUpper(symbol), interval, closePrice, period, value, ts)
-			logx.Infof("RSI signal sent at %s: %s", time.Now().Format("2006-01-02 15:04:05"), msg)
-			notification.SendNotification(msg, "rsi")
-
-			// 检测小实体（开盘与收盘几乎相等），针对 4h/1d/1M 触发
-			// 阈值采用相对开盘价的百分比，默认 0.1%
-			if interval == "4h" || interval == "1d" || interval == "1M" {
-				// 保护：避免除零
-				denominator := math.Abs(openPrice)
-				if denominator > 0 {
-					relativeDiff := math.Abs(closePrice-openPrice) / denominator
-					threshold := 0.001 // 0.1%
-					if relativeDiff <= threshold {
-						title := "小实体告警"
-						body := fmt.Sprintf("%s %s 开收盘接近 (|O-C|/O=%.4f%%)\nO=%.2f C=%.2f @ %s", strings.ToUpper(symbol), interval, relativeDiff*100, openPrice, closePrice, ts)
-						logx.Infof("Doji-like body detected at %s: %s", time.Now().Format("2006-01-02 15:04:05"), body)
-						_ = notification.SendNotificationWithTitle(body, title, "doji")
-					}
-				}
-			}
+	// 成交额相对最近K线放量
+	if st.volume != nil {
+		if spike, ok := st.volume.update(c); ok {
+			sendVolumeAlert(symbol, interval, spike)
 		}
-		// loop to reconnect
 	}
 }
 
-func minDuration(a, b time.Duration) time.Duration {
-	if a < b {
-		return a
+// backfill 拉取开盘时间在 [from, to] 内缺失的K线，静默更新指标、形态和成交量状态并写入存储
+func (w *watcher) backfill(st *watchState, from, to int64) {
+	baseURL, _, _ := klineSettings()
+	missing, err := fetchKlineRange(baseURL, w.symbol, w.interval, from, to)
+	if err != nil {
+		w.setError(err)
+		logx.Errorf("Failed to backfill klines %s %s: %v", strings.ToUpper(w.symbol), w.interval, err)
+		return
 	}
-	return b
+	for _, c := range missing {
+		if c.CloseTime <= st.lastTs || c.CloseTime >= time.Now().UnixMilli() {
+			continue
+		}
+		st.set.update(c)
+		if st.patterns != nil {
+			st.patterns.update(c)
+		}
+		if st.volume != nil {
+			st.volume.update(c)
+		}
+		st.lastTs = c.CloseTime
+	}
+	saveCandles(w.symbol, w.interval, closedCandles(missing))
+	logx.Infof("Backfilled %d klines %s %s before %s", len(missing), strings.ToUpper(w.symbol), w.interval,
+		time.UnixMilli(to+1).Format(time.RFC3339))
 }
 
 // Client 表示单个 SSE 客户端连接
@@ -331,7 +384,7 @@ type SseHandler struct {
 }
 
 func NewSseHandler() *SseHandler {
-	return &SseHandler{broker: NewBroker()}
+	return &SseHandler{broker: getFeed()}
 }
 
 // Serve 处理 SSE 连接
@@ -0,0 +1,185 @@
+package rsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CandleStore 历史K线缓存，重启后预热只需补齐缓存之后的K线
+type CandleStore interface {
+	// Load 返回最近 limit 根已缓存的K线，按开盘时间升序
+	Load(symbol, interval string, limit int) ([]Candle, error)
+	// Save 保存已收盘的K线，开盘时间相同的K线覆盖
+	Save(symbol, interval string, candles []Candle) error
+}
+
+// dbCandleStore 基于 Postgres candles 表的K线存储，实时收盘的K线也写入该表
+type dbCandleStore struct {
+	db *gorm.DB
+}
+
+// NewDBCandleStore 创建基于数据库的K线存储
+func NewDBCandleStore(db *gorm.DB) CandleStore {
+	return &dbCandleStore{db: db}
+}
+
+func (s *dbCandleStore) Load(symbol, interval string, limit int) ([]Candle, error) {
+	var rows []model.Candle
+	q := s.db.Where(&model.Candle{Symbol: strings.ToUpper(symbol), Interval: interval}).Order("close_time DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load candles: %w", err)
+	}
+
+	candles := make([]Candle, len(rows))
+	for i, r := range rows {
+		// 查询结果按收盘时间倒序，转为升序
+		candles[len(rows)-1-i] = Candle{
+			OpenTime:  r.OpenTime.UnixMilli(),
+			Open:      r.Open,
+			High:      r.High,
+			Low:       r.Low,
+			Close:     r.Close,
+			Volume:    r.Volume,
+			CloseTime: r.CloseTime.UnixMilli(),
+
+			QuoteVolume:         r.QuoteVolume,
+			TakerBuyVolume:      r.TakerBuyVolume,
+			TakerBuyQuoteVolume: r.TakerBuyQuoteVolume,
+		}
+	}
+	return candles, nil
+}
+
+func (s *dbCandleStore) Save(symbol, interval string, candles []Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	rows := make([]model.Candle, len(candles))
+	for i, c := range candles {
+		rows[i] = model.Candle{
+			Symbol:    strings.ToUpper(symbol),
+			Interval:  interval,
+			OpenTime:  time.UnixMilli(c.OpenTime),
+			CloseTime: time.UnixMilli(c.CloseTime),
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+
+			QuoteVolume:         c.QuoteVolume,
+			TakerBuyVolume:      c.TakerBuyVolume,
+			TakerBuyQuoteVolume: c.TakerBuyQuoteVolume,
+		}
+	}
+	// 按收盘时间 upsert，重复写入同一根K线时覆盖价格和成交量
+	updates := []string{"open_time", "open", "high", "low", "close", "volume",
+		"quote_volume", "taker_buy_volume", "taker_buy_quote_volume", "updated_at", "deleted_at"}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}, {Name: "interval"}, {Name: "close_time"}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).CreateInBatches(rows, 500).Error
+}
+
+// defaultFileCandleLimit 本地缓存每个交易对/周期默认保留的K线数量
+const defaultFileCandleLimit = 5000
+
+// fileCandleStore 每个交易对/周期一个 JSON 文件，只保留最近 limit 根K线
+type fileCandleStore struct {
+	dir   string
+	limit int
+	mutex sync.Mutex
+}
+
+// NewFileCandleStore 创建基于本地 JSON 文件的K线缓存，每个文件保留最近 5000 根K线
+func NewFileCandleStore(dir string) CandleStore {
+	return newFileCandleStore(dir, defaultFileCandleLimit)
+}
+
+func newFileCandleStore(dir string, limit int) *fileCandleStore {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Printf("Failed to create kline cache directory: %v\n", err)
+	}
+	if limit <= 0 {
+		limit = defaultFileCandleLimit
+	}
+	return &fileCandleStore{dir: dir, limit: limit}
+}
+
+// path 文件名区分 1m 和 1M，避免在大小写不敏感的文件系统上冲突
+func (s *fileCandleStore) path(symbol, interval string) string {
+	name := strings.ToUpper(symbol) + "_" + strings.ReplaceAll(interval, "M", "mo") + ".json"
+	return filepath.Join(s.dir, name)
+}
+
+func (s *fileCandleStore) load(path string) ([]Candle, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var candles []Candle
+	if err := json.Unmarshal(data, &candles); err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
+
+func (s *fileCandleStore) Load(symbol, interval string, limit int) ([]Candle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	candles, err := s.load(s.path(symbol, interval))
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+func (s *fileCandleStore) Save(symbol, interval string, candles []Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := s.path(symbol, interval)
+	existing, err := s.load(path)
+	if err != nil {
+		// 缓存损坏时直接覆盖
+		existing = nil
+	}
+	// 每次保存都要重写整个文件，只保留最近 limit 根，避免文件无限增长
+	merged := mergeCandles(existing, candles)
+	if len(merged) > s.limit {
+		merged = merged[len(merged)-s.limit:]
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免写入中断留下不完整的文件
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,239 @@
+package rsi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// confluenceRule 校验并补全默认值后的共振规则
+type confluenceRule struct {
+	name       string
+	symbols    map[string]bool // 为空匹配全部
+	intervals  []string
+	minMatches int
+	condition  string // signalOversold / signalOverbought
+	threshold  float64
+	hysteresis float64
+	keepSingle bool
+}
+
+// confluenceAlert 一次共振告警
+type confluenceAlert struct {
+	rule    *confluenceRule
+	symbol  string
+	values  map[string]float64 // 参与规则的各周期最新 RSI
+	matched []string           // 满足条件的周期
+}
+
+// confluenceEvaluator 保存每个交易对各周期的最新 RSI，按规则判断多周期共振
+type confluenceEvaluator struct {
+	mu     sync.Mutex
+	rules  []*confluenceRule
+	latest map[string]map[string]float64 // SYMBOL -> interval -> RSI
+	active map[string]bool               // 规则+交易对 -> 当前是否处于共振状态
+}
+
+var confluence = newConfluenceEvaluator()
+
+func newConfluenceEvaluator() *confluenceEvaluator {
+	return &confluenceEvaluator{
+		latest: make(map[string]map[string]float64),
+		active: make(map[string]bool),
+	}
+}
+
+// SetConfluenceRules 设置多周期共振规则，应在启动 RSI 任务前调用
+func SetConfluenceRules(rules []config.ConfluenceRule) {
+	confluence.setRules(rules)
+}
+
+func (e *confluenceEvaluator) setRules(rules []config.ConfluenceRule) {
+	var parsed []*confluenceRule
+	for i, r := range rules {
+		rule, err := parseConfluenceRule(r)
+		if err != nil {
+			logx.Errorf("Skip invalid confluence rule #%d %s: %v", i, r.Name, err)
+			continue
+		}
+		parsed = append(parsed, rule)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = parsed
+	e.active = make(map[string]bool)
+}
+
+func parseConfluenceRule(r config.ConfluenceRule) (*confluenceRule, error) {
+	if len(r.Intervals) < 2 {
+		return nil, fmt.Errorf("at least 2 intervals are required")
+	}
+	rule := &confluenceRule{
+		name:       r.Name,
+		intervals:  r.Intervals,
+		minMatches: r.MinMatches,
+		threshold:  r.Threshold,
+		hysteresis: r.Hysteresis,
+		keepSingle: r.KeepSingle,
+	}
+	if rule.minMatches <= 0 || rule.minMatches > len(r.Intervals) {
+		rule.minMatches = len(r.Intervals)
+	}
+	if rule.hysteresis <= 0 {
+		rule.hysteresis = 2
+	}
+	switch strings.ToLower(r.Condition) {
+	case "", signalOversold:
+		rule.condition = signalOversold
+		if rule.threshold <= 0 {
+			rule.threshold = 30
+		}
+	case signalOverbought:
+		rule.condition = signalOverbought
+		if rule.threshold <= 0 {
+			rule.threshold = 70
+		}
+	default:
+		return nil, fmt.Errorf("unknown condition %q", r.Condition)
+	}
+	if rule.name == "" {
+		rule.name = fmt.Sprintf("%s %s", strings.Join(r.Intervals, "/"), rule.condition)
+	}
+	if len(r.Symbols) > 0 {
+		rule.symbols = make(map[string]bool, len(r.Symbols))
+		for _, s := range r.Symbols {
+			rule.symbols[strings.ToUpper(s)] = true
+		}
+	}
+	return rule, nil
+}
+
+func (r *confluenceRule) appliesTo(symbol, interval string) bool {
+	if r.symbols != nil && !r.symbols[symbol] {
+		return false
+	}
+	for _, i := range r.intervals {
+		if i == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// inZone 判断 RSI 是否满足条件；margin 为正时放宽阈值（用于已处于共振状态时的回差）
+func (r *confluenceRule) inZone(v, margin float64) bool {
+	if r.condition == signalOversold {
+		return v <= r.threshold+margin
+	}
+	return v >= r.threshold-margin
+}
+
+// update 记录交易对/周期的最新 RSI，返回新进入共振状态的规则告警；silent 为 true 时（预热）只更新状态
+func (e *confluenceEvaluator) update(symbol, interval string, rsi float64, silent bool) []confluenceAlert {
+	symbol = strings.ToUpper(symbol)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.latest[symbol] == nil {
+		e.latest[symbol] = make(map[string]float64)
+	}
+	e.latest[symbol][interval] = rsi
+
+	var alerts []confluenceAlert
+	for _, rule := range e.rules {
+		if !rule.appliesTo(symbol, interval) {
+			continue
+		}
+
+		key := rule.name + "|" + symbol
+		active := e.active[key]
+		margin := 0.0
+		if active {
+			margin = rule.hysteresis
+		}
+
+		values := make(map[string]float64, len(rule.intervals))
+		var matched []string
+		for _, i := range rule.intervals {
+			v, ok := e.latest[symbol][i]
+			if !ok {
+				continue
+			}
+			values[i] = v
+			if rule.inZone(v, margin) {
+				matched = append(matched, i)
+			}
+		}
+
+		if len(matched) < rule.minMatches {
+			e.active[key] = false
+			continue
+		}
+		e.active[key] = true
+		if active || silent {
+			continue
+		}
+		alerts = append(alerts, confluenceAlert{rule: rule, symbol: symbol, values: values, matched: matched})
+	}
+	return alerts
+}
+
+// suppresses 交易对/周期是否被共振规则覆盖，覆盖时不再单独推送该周期的 RSI 阈值告警
+func (e *confluenceEvaluator) suppresses(symbol, interval string) bool {
+	symbol = strings.ToUpper(symbol)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, rule := range e.rules {
+		if !rule.keepSingle && rule.appliesTo(symbol, interval) {
+			return true
+		}
+	}
+	return false
+}
+
+// remove 监控停止后清除该周期的状态，避免过期读数参与判断
+func (e *confluenceEvaluator) remove(symbol, interval string) {
+	symbol = strings.ToUpper(symbol)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.latest[symbol], interval)
+}
+
+// sendConfluenceAlert 推送多周期共振告警
+func sendConfluenceAlert(a confluenceAlert, closePrice float64, closeTime time.Time) {
+	rule := a.rule
+	zone, op := "超卖", "<"
+	if rule.condition == signalOverbought {
+		zone, op = "超买", ">"
+	}
+
+	var parts []string
+	for _, i := range rule.intervals {
+		if v, ok := a.values[i]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%.2f", i, v))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=-", i))
+		}
+	}
+
+	title := fmt.Sprintf("RSI多周期共振%s %s", zone, a.symbol)
+	body := fmt.Sprintf("%s RSI %s %.0f: %d/%d 个周期 (%s)\n%s\n规则: %s\nclose=%.2f @ %s",
+		a.symbol, op, rule.threshold, len(a.matched), len(rule.intervals), strings.Join(a.matched, ", "),
+		strings.Join(parts, " "), rule.name, closePrice, closeTime.Format(time.RFC3339))
+
+	logx.Infof("RSI confluence alert: %s", strings.ReplaceAll(body, "\n", " "))
+	if err := sendAlert(body, title, topic.Of("confluence", a.symbol)); err != nil {
+		logx.Errorf("Failed to send confluence alert: %v", err)
+	}
+}
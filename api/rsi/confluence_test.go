@@ -0,0 +1,88 @@
+package rsi
+
+import (
+	"testing"
+
+	"notice/api/config"
+)
+
+func TestConfluenceAllIntervalsAgree(t *testing.T) {
+	e := newConfluenceEvaluator()
+	e.setRules([]config.ConfluenceRule{
+		{Name: "weekly oversold", Symbols: []string{"btcusdt"}, Intervals: []string{"4h", "1d", "1w"}},
+	})
+
+	if alerts := e.update("btcusdt", "4h", 25, false); len(alerts) != 0 {
+		t.Fatalf("one interval should not fire: %+v", alerts)
+	}
+	if alerts := e.update("btcusdt", "1d", 28, false); len(alerts) != 0 {
+		t.Fatalf("two intervals should not fire: %+v", alerts)
+	}
+	alerts := e.update("BTCUSDT", "1w", 29.5, false)
+	if len(alerts) != 1 || len(alerts[0].matched) != 3 || alerts[0].symbol != "BTCUSDT" {
+		t.Fatalf("expected one alert with 3 matches, got %+v", alerts)
+	}
+
+	// 仍处于共振状态（回差内）不重复告警
+	if alerts := e.update("btcusdt", "4h", 31, false); len(alerts) != 0 {
+		t.Fatalf("should not repeat within hysteresis: %+v", alerts)
+	}
+	// 回落到回差之外后解除，再次满足时重新告警
+	if alerts := e.update("btcusdt", "4h", 35, false); len(alerts) != 0 {
+		t.Fatalf("unexpected alert on exit: %+v", alerts)
+	}
+	if alerts := e.update("btcusdt", "4h", 29, false); len(alerts) != 1 {
+		t.Fatalf("expected alert after re-entering, got %+v", alerts)
+	}
+
+	// 其他交易对不受该规则影响
+	for _, i := range []string{"4h", "1d", "1w"} {
+		if alerts := e.update("ethusdt", i, 20, false); len(alerts) != 0 {
+			t.Fatalf("rule should only apply to BTCUSDT: %+v", alerts)
+		}
+	}
+}
+
+func TestConfluenceMinMatchesAndSilentWarmup(t *testing.T) {
+	e := newConfluenceEvaluator()
+	e.setRules([]config.ConfluenceRule{
+		{Intervals: []string{"4h", "1d", "1w"}, MinMatches: 2, Condition: "overbought", KeepSingle: true},
+		{Intervals: []string{"2h"}},                          // 少于两个周期，忽略
+		{Intervals: []string{"1d", "1w"}, Condition: "flat"}, // 未知条件，忽略
+	})
+	if len(e.rules) != 1 || e.rules[0].threshold != 70 {
+		t.Fatalf("unexpected parsed rules: %+v", e.rules)
+	}
+
+	// 预热时满足条件只更新状态，不告警，之后也不会重复告警
+	e.update("ethusdt", "4h", 75, true)
+	if alerts := e.update("ethusdt", "1d", 72, true); len(alerts) != 0 {
+		t.Fatalf("silent update should not alert: %+v", alerts)
+	}
+	if alerts := e.update("ethusdt", "1w", 80, false); len(alerts) != 0 {
+		t.Fatalf("already active after warmup: %+v", alerts)
+	}
+
+	e.update("solusdt", "4h", 71, false)
+	if alerts := e.update("solusdt", "1w", 73, false); len(alerts) != 1 {
+		t.Fatalf("expected 2 of 3 intervals to fire, got %+v", alerts)
+	}
+	if e.suppresses("solusdt", "4h") {
+		t.Fatal("KeepSingle rule should not suppress single-interval alerts")
+	}
+}
+
+func TestConfluenceSuppressesAndRemove(t *testing.T) {
+	e := newConfluenceEvaluator()
+	e.setRules([]config.ConfluenceRule{{Intervals: []string{"4h", "1d"}}})
+
+	if !e.suppresses("btcusdt", "4h") || e.suppresses("btcusdt", "2h") {
+		t.Fatal("rule intervals should suppress single alerts, others should not")
+	}
+
+	e.update("btcusdt", "4h", 20, false)
+	e.remove("btcusdt", "4h")
+	if alerts := e.update("btcusdt", "1d", 20, false); len(alerts) != 0 {
+		t.Fatalf("removed interval should not count: %+v", alerts)
+	}
+}
@@ -0,0 +1,102 @@
+package rsi
+
+// 背离信号类型
+const (
+	signalBullishDivergence       = "bullish_divergence"        // 价格更低的低点，RSI 更高的低点
+	signalBearishDivergence       = "bearish_divergence"        // 价格更高的高点，RSI 更低的高点
+	signalHiddenBullishDivergence = "hidden_bullish_divergence" // 价格更高的低点，RSI 更低的低点
+	signalHiddenBearishDivergence = "hidden_bearish_divergence" // 价格更低的高点，RSI 更高的高点
+)
+
+// pivot 摆动点（局部高点或低点）及当时的 RSI
+type pivot struct {
+	bar   int // 第几根K线（从预热开始计数）
+	price float64
+	rsi   float64
+	time  int64 // K线收盘时间（毫秒）
+}
+
+// divergence 两个摆动点之间的背离
+type divergence struct {
+	kind string
+	prev pivot
+	curr pivot
+}
+
+type divergenceBar struct {
+	candle Candle
+	rsi    float64
+}
+
+// divergenceDetector 在价格与 RSI 的摆动点之间检测常规背离和隐藏背离。
+// 一根K线的最低价（最高价）是左右各 strength 根K线内的最低（最高）时视为摆动点，
+// 因此摆动点在 strength 根K线后才能确认；只比较相距不超过 lookback 根K线的相邻摆动点
+type divergenceDetector struct {
+	strength int
+	lookback int
+
+	bars     []divergenceBar
+	count    int
+	lastLow  *pivot
+	lastHigh *pivot
+}
+
+func newDivergenceDetector(strength, lookback int) *divergenceDetector {
+	return &divergenceDetector{strength: strength, lookback: lookback}
+}
+
+// update 输入一根收盘K线及其 RSI，确认新的摆动点时返回检测到的背离
+func (d *divergenceDetector) update(c Candle, rsi float64) []divergence {
+	d.bars = append(d.bars, divergenceBar{candle: c, rsi: rsi})
+	d.count++
+	window := 2*d.strength + 1
+	if len(d.bars) > window {
+		d.bars = d.bars[len(d.bars)-window:]
+	}
+	if len(d.bars) < window {
+		return nil
+	}
+
+	// 窗口中间的K线左右各有 strength 根K线，可以确认是否为摆动点
+	mid := d.bars[d.strength]
+	isLow, isHigh := true, true
+	for i, b := range d.bars {
+		if i == d.strength {
+			continue
+		}
+		if b.candle.Low <= mid.candle.Low {
+			isLow = false
+		}
+		if b.candle.High >= mid.candle.High {
+			isHigh = false
+		}
+	}
+
+	bar := d.count - 1 - d.strength
+	var found []divergence
+	if isLow {
+		p := pivot{bar: bar, price: mid.candle.Low, rsi: mid.rsi, time: mid.candle.CloseTime}
+		if prev := d.lastLow; prev != nil && bar-prev.bar <= d.lookback {
+			switch {
+			case p.price < prev.price && p.rsi > prev.rsi:
+				found = append(found, divergence{kind: signalBullishDivergence, prev: *prev, curr: p})
+			case p.price > prev.price && p.rsi < prev.rsi:
+				found = append(found, divergence{kind: signalHiddenBullishDivergence, prev: *prev, curr: p})
+			}
+		}
+		d.lastLow = &p
+	}
+	if isHigh {
+		p := pivot{bar: bar, price: mid.candle.High, rsi: mid.rsi, time: mid.candle.CloseTime}
+		if prev := d.lastHigh; prev != nil && bar-prev.bar <= d.lookback {
+			switch {
+			case p.price > prev.price && p.rsi < prev.rsi:
+				found = append(found, divergence{kind: signalBearishDivergence, prev: *prev, curr: p})
+			case p.price < prev.price && p.rsi > prev.rsi:
+				found = append(found, divergence{kind: signalHiddenBearishDivergence, prev: *prev, curr: p})
+			}
+		}
+		d.lastHigh = &p
+	}
+	return found
+}
@@ -0,0 +1,58 @@
+package rsi
+
+import "testing"
+
+type divBar struct {
+	low, high, rsi float64
+}
+
+func feedDivergence(d *divergenceDetector, bars []divBar) []divergence {
+	var found []divergence
+	for i, b := range bars {
+		c := Candle{Low: b.low, High: b.high, Close: (b.low + b.high) / 2, CloseTime: int64(i+1) * 60000}
+		found = append(found, d.update(c, b.rsi)...)
+	}
+	return found
+}
+
+func TestDivergenceRegularBullish(t *testing.T) {
+	// 第二个低点价格更低（4 < 5），RSI 更高（25 > 20）
+	bars := []divBar{
+		{10, 11, 40}, {9, 10, 35}, {5, 6, 20}, {9, 10, 35}, {10, 11, 40},
+		{9, 10, 38}, {4, 5, 25}, {9, 10, 36}, {10, 11, 40},
+	}
+	found := feedDivergence(newDivergenceDetector(2, 60), bars)
+	if len(found) != 1 {
+		t.Fatalf("expected 1 divergence, got %+v", found)
+	}
+	d := found[0]
+	if d.kind != signalBullishDivergence || d.prev.price != 5 || d.curr.price != 4 || d.prev.rsi != 20 || d.curr.rsi != 25 {
+		t.Fatalf("unexpected divergence: %+v", d)
+	}
+	if d.curr.time != 7*60000 {
+		t.Fatalf("pivot time = %d, want the pivot candle", d.curr.time)
+	}
+}
+
+func TestDivergenceHiddenBearish(t *testing.T) {
+	// 第二个高点价格更低（14 < 15），RSI 更高（75 > 70）
+	bars := []divBar{
+		{9, 10, 50}, {10, 11, 55}, {14, 15, 70}, {10, 11, 55}, {9, 10, 50},
+		{10, 11, 56}, {13, 14, 75}, {10, 11, 55}, {9, 10, 50},
+	}
+	found := feedDivergence(newDivergenceDetector(2, 60), bars)
+	if len(found) != 1 || found[0].kind != signalHiddenBearishDivergence {
+		t.Fatalf("expected hidden bearish divergence, got %+v", found)
+	}
+}
+
+func TestDivergenceOutsideLookback(t *testing.T) {
+	bars := []divBar{
+		{10, 11, 40}, {9, 10, 35}, {5, 6, 20}, {9, 10, 35}, {10, 11, 40},
+		{9, 10, 38}, {4, 5, 25}, {9, 10, 36}, {10, 11, 40},
+	}
+	// 两个低点相距 4 根K线，超过 lookback=3
+	if found := feedDivergence(newDivergenceDetector(2, 3), bars); len(found) != 0 {
+		t.Fatalf("expected no divergence outside lookback, got %+v", found)
+	}
+}
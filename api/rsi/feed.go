@@ -0,0 +1,87 @@
+package rsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reading 某交易对/周期最近一次收盘的 RSI 读数
+type Reading struct {
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`
+	Period    int       `json:"period"`
+	Close     float64   `json:"close"`
+	RSI       float64   `json:"rsi"`
+	CloseTime time.Time `json:"close_time"`
+
+	Indicators map[string]float64 `json:"indicators,omitempty"` // 所有指标读数，key 为指标名称
+}
+
+var (
+	feedOnce   sync.Once
+	feedBroker *Broker
+
+	latestMu sync.RWMutex
+	latest   = make(map[string]Reading) // key: SYMBOL@interval
+)
+
+// getFeed 返回全局 SSE 广播器，每根 K 线收盘的 RSI 读数通过它推送给 SSE 客户端
+func getFeed() *Broker {
+	feedOnce.Do(func() {
+		feedBroker = NewBroker()
+	})
+	return feedBroker
+}
+
+// publishReading 记录最新读数并广播到 SSE，不发送推送通知
+func publishReading(r Reading) {
+	latestMu.Lock()
+	latest[r.Symbol+"@"+r.Interval] = r
+	latestMu.Unlock()
+
+	getFeed().Broadcast(fmt.Sprintf("%s %s close=%.2f %s @ %s",
+		r.Symbol, r.Interval, r.Close, formatValues(r.Indicators), r.CloseTime.Format(time.RFC3339)))
+}
+
+// formatValues 按指标名称排序格式化读数，如 "MACD(12,26,9)=12.3400 RSI(14)=55.30"
+func formatValues(values map[string]float64) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, "RSI") {
+			parts = append(parts, fmt.Sprintf("%s=%.2f", name, values[name]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%.4f", name, values[name]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// GetLatestReadings 返回所有交易对/周期最近一次收盘的 RSI 读数，symbol 为空时返回全部
+func GetLatestReadings(symbol string) []Reading {
+	latestMu.RLock()
+	defer latestMu.RUnlock()
+
+	readings := make([]Reading, 0, len(latest))
+	for _, r := range latest {
+		if symbol != "" && !strings.EqualFold(r.Symbol, symbol) {
+			continue
+		}
+		readings = append(readings, r)
+	}
+	sort.Slice(readings, func(i, j int) bool {
+		if readings[i].Symbol != readings[j].Symbol {
+			return readings[i].Symbol < readings[j].Symbol
+		}
+		return readings[i].Interval < readings[j].Interval
+	})
+	return readings
+}
@@ -0,0 +1,198 @@
+package rsi
+
+import (
+	"fmt"
+	"math"
+)
+
+// Candle 指标计算使用的收盘K线
+type Candle struct {
+	OpenTime  int64   `json:"open_time"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	CloseTime int64   `json:"close_time"`
+	// 成交额和主动买入量，历史数据缺失时为 0
+	QuoteVolume         float64 `json:"quote_volume,omitempty"`
+	TakerBuyVolume      float64 `json:"taker_buy_volume,omitempty"`
+	TakerBuyQuoteVolume float64 `json:"taker_buy_quote_volume,omitempty"`
+}
+
+// candle 将 K 线推送事件转换为 Candle
+func (ev binanceKline) candle() Candle {
+	return Candle{
+		OpenTime:  ev.K.OpenTime,
+		Open:      toFloat(ev.K.Open),
+		High:      toFloat(ev.K.High),
+		Low:       toFloat(ev.K.Low),
+		Close:     toFloat(ev.K.Close),
+		Volume:    toFloat(ev.K.Volume),
+		CloseTime: ev.K.CloseTime,
+
+		QuoteVolume:         toFloat(ev.K.QuoteAssetVolume),
+		TakerBuyVolume:      toFloat(ev.K.TakerBuyBaseAssetVolume),
+		TakerBuyQuoteVolume: toFloat(ev.K.TakerBuyQuoteAssetVolume),
+	}
+}
+
+// Indicator 增量计算的技术指标，每根收盘K线调用一次 Update
+type Indicator interface {
+	// Name 指标名称，如 RSI(14)、MACD(12,26,9)
+	Name() string
+	// Update 输入一根收盘K线，返回指标的主读数；预热未完成时 ok 为 false
+	Update(c Candle) (value float64, ok bool)
+}
+
+// ---- RSI ----
+
+func (r *rsiCalc) Name() string { return fmt.Sprintf("RSI(%d)", r.period) }
+
+func (r *rsiCalc) Update(c Candle) (float64, bool) { return r.add(c.Close) }
+
+// ---- EMA ----
+
+// emaCalc 指数移动平均，前 period 个值的简单平均作为初始值
+type emaCalc struct {
+	period int
+	k      float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+func newEMA(period int) *emaCalc {
+	return &emaCalc{period: period, k: 2 / float64(period+1)}
+}
+
+func (e *emaCalc) add(v float64) (float64, bool) {
+	if e.count < e.period {
+		e.count++
+		e.sum += v
+		if e.count < e.period {
+			return 0, false
+		}
+		e.value = e.sum / float64(e.period)
+		return e.value, true
+	}
+	e.value = (v-e.value)*e.k + e.value
+	return e.value, true
+}
+
+func (e *emaCalc) Name() string { return fmt.Sprintf("EMA(%d)", e.period) }
+
+func (e *emaCalc) Update(c Candle) (float64, bool) { return e.add(c.Close) }
+
+// ---- EMA 交叉 ----
+
+// emaCross 快慢 EMA，主读数为 快线-慢线，由负转正为金叉，由正转负为死叉
+type emaCross struct {
+	fast *emaCalc
+	slow *emaCalc
+}
+
+func newEMACross(fast, slow int) *emaCross {
+	return &emaCross{fast: newEMA(fast), slow: newEMA(slow)}
+}
+
+func (e *emaCross) Name() string {
+	return fmt.Sprintf("EMA(%d/%d)", e.fast.period, e.slow.period)
+}
+
+func (e *emaCross) Update(c Candle) (float64, bool) {
+	f, fastOK := e.fast.add(c.Close)
+	s, slowOK := e.slow.add(c.Close)
+	if !fastOK || !slowOK {
+		return 0, false
+	}
+	return f - s, true
+}
+
+// ---- MACD ----
+
+// macdCalc MACD，主读数为柱状图（MACD 线 - 信号线）
+type macdCalc struct {
+	fast   *emaCalc
+	slow   *emaCalc
+	signal *emaCalc
+
+	macd float64
+	sig  float64
+}
+
+func newMACD(fast, slow, signal int) *macdCalc {
+	return &macdCalc{fast: newEMA(fast), slow: newEMA(slow), signal: newEMA(signal)}
+}
+
+func (m *macdCalc) Name() string {
+	return fmt.Sprintf("MACD(%d,%d,%d)", m.fast.period, m.slow.period, m.signal.period)
+}
+
+func (m *macdCalc) Update(c Candle) (float64, bool) {
+	f, fastOK := m.fast.add(c.Close)
+	s, slowOK := m.slow.add(c.Close)
+	if !fastOK || !slowOK {
+		return 0, false
+	}
+	m.macd = f - s
+	sig, ok := m.signal.add(m.macd)
+	if !ok {
+		return 0, false
+	}
+	m.sig = sig
+	return m.macd - m.sig, true
+}
+
+// ---- 布林带 ----
+
+// bollingerCalc 布林带，主读数为 %B：(收盘价-下轨)/(上轨-下轨)，大于1为突破上轨，小于0为跌破下轨
+type bollingerCalc struct {
+	period int
+	stdDev float64
+	window []float64
+	next   int
+	filled bool
+
+	middle float64
+	upper  float64
+	lower  float64
+}
+
+func newBollinger(period int, stdDev float64) *bollingerCalc {
+	return &bollingerCalc{period: period, stdDev: stdDev, window: make([]float64, period)}
+}
+
+func (b *bollingerCalc) Name() string {
+	return fmt.Sprintf("BOLL(%d,%g)", b.period, b.stdDev)
+}
+
+func (b *bollingerCalc) Update(c Candle) (float64, bool) {
+	b.window[b.next] = c.Close
+	b.next = (b.next + 1) % b.period
+	if b.next == 0 {
+		b.filled = true
+	}
+	if !b.filled {
+		return 0, false
+	}
+
+	var sum float64
+	for _, v := range b.window {
+		sum += v
+	}
+	mean := sum / float64(b.period)
+	var variance float64
+	for _, v := range b.window {
+		variance += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(variance / float64(b.period))
+
+	b.middle = mean
+	b.upper = mean + b.stdDev*sd
+	b.lower = mean - b.stdDev*sd
+	if b.upper == b.lower {
+		return 0.5, true
+	}
+	return (c.Close - b.lower) / (b.upper - b.lower), true
+}
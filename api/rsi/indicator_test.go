@@ -0,0 +1,130 @@
+package rsi
+
+import (
+	"math"
+	"testing"
+
+	"notice/api/config"
+)
+
+func closes(values ...float64) []Candle {
+	candles := make([]Candle, len(values))
+	for i, v := range values {
+		candles[i] = Candle{Open: v, Close: v, CloseTime: int64(i+1) * 60000}
+	}
+	return candles
+}
+
+// vShape 先加速下跌再快速上涨的收盘价序列
+func vShape(n int) []float64 {
+	var values []float64
+	low := 100.0
+	for i := 0; i < n; i++ {
+		low = 100 - float64(i*i)*0.1
+		values = append(values, low)
+	}
+	for i := 1; i <= n; i++ {
+		values = append(values, low+float64(i)*3)
+	}
+	return values
+}
+
+func TestEMASeededWithSMA(t *testing.T) {
+	e := newEMA(3)
+	var v float64
+	var ok bool
+	for _, c := range closes(1, 2, 3, 4) {
+		v, ok = e.Update(c)
+	}
+	if !ok || v != 3 {
+		t.Fatalf("EMA(3) = %v (ok=%v), want 3", v, ok)
+	}
+	if e.Name() != "EMA(3)" {
+		t.Fatalf("unexpected name %q", e.Name())
+	}
+}
+
+func TestBollingerPercentB(t *testing.T) {
+	b := newBollinger(5, 1.5)
+	var v float64
+	var ok bool
+	for _, c := range closes(10, 10, 10, 10) {
+		if _, ok = b.Update(c); ok {
+			t.Fatal("bollinger should not be ready before the window is filled")
+		}
+	}
+	if v, ok = b.Update(closes(10)[0]); !ok || v != 0.5 {
+		t.Fatalf("flat %%B = %v, want 0.5", v)
+	}
+	v, _ = b.Update(closes(20)[0])
+	if v <= 1 || math.Abs(b.upper-18) > 1e-9 || math.Abs(b.middle-12) > 1e-9 {
+		t.Fatalf("breakout %%B = %v middle=%v upper=%v", v, b.middle, b.upper)
+	}
+}
+
+func TestCrossAlert(t *testing.T) {
+	a := newCrossAlert(signalGoldenCross, signalDeathCross)
+	steps := []struct {
+		value float64
+		want  string
+	}{
+		{0, ""},
+		{-1, ""}, // 初始化方向
+		{-2, ""},
+		{0, ""}, // 0 保持原方向
+		{1, signalGoldenCross},
+		{2, ""},
+		{-0.5, signalDeathCross},
+	}
+	for i, s := range steps {
+		if got := a.update(s.value); got != s.want {
+			t.Fatalf("step %d: update(%v) = %q, want %q", i, s.value, got, s.want)
+		}
+	}
+}
+
+func TestIndicatorSetSignals(t *testing.T) {
+	set, err := newIndicatorSet([]config.IndicatorConfig{
+		{Type: "macd", Fast: 3, Slow: 6, Signal: 3},
+		{Type: "ema_cross", Fast: 3, Slow: 6},
+		{Type: "bollinger", Period: 5, StdDev: 1},
+	}, 14, thresholds{overbought: 70, oversold: 30, hysteresis: 2})
+	if err != nil {
+		t.Fatalf("newIndicatorSet: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, c := range closes(vShape(20)...) {
+		for _, sig := range set.update(c) {
+			got[sig.signal] = true
+		}
+	}
+	for _, want := range []string{signalBullish, signalGoldenCross, signalUpperBreakout} {
+		if !got[want] {
+			t.Errorf("expected %s signal, got %v", want, got)
+		}
+	}
+	if got[signalDeathCross] {
+		t.Errorf("unexpected death cross on V-shaped series: %v", got)
+	}
+	if !set.ready() || len(set.values()) != 3 {
+		t.Fatalf("expected all indicators ready, values=%v", set.values())
+	}
+	if _, _, ok := set.rsi(); ok {
+		t.Fatal("set without rsi should not report an rsi value")
+	}
+}
+
+func TestIndicatorSetDefaultsAndErrors(t *testing.T) {
+	set, err := newIndicatorSet(nil, 21, thresholds{overbought: 70, oversold: 30, hysteresis: 2})
+	if err != nil || len(set.names()) != 1 || set.names()[0] != "RSI(21)" {
+		t.Fatalf("default set: names=%v err=%v", set.names(), err)
+	}
+
+	if _, err := newIndicatorSet([]config.IndicatorConfig{{Type: "macd", Fast: 26, Slow: 12}}, 14, thresholds{}); err == nil {
+		t.Fatal("expected error for fast >= slow")
+	}
+	if _, err := newIndicatorSet([]config.IndicatorConfig{{Type: "kdj"}}, 14, thresholds{}); err == nil {
+		t.Fatal("expected error for unknown indicator")
+	}
+}
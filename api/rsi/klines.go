@@ -0,0 +1,304 @@
+package rsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	defaultKlineBaseURL = "https://fapi.binance.com"
+	defaultKlineCache   = "./storage/klines"
+	defaultWarmup       = 1000
+
+	// klinePageLimit fapi/v1/klines 单次请求的最大K线数量
+	klinePageLimit = 1500
+)
+
+var (
+	klineMu      sync.RWMutex
+	klineBaseURL = defaultKlineBaseURL
+	klineWarmup  = defaultWarmup
+	candleStore  CandleStore // 为 nil 时不缓存
+
+	klineHTTPClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// SetKlineConfig 设置历史K线的 REST 地址、预热数量和本地缓存，应在启动 RSI 任务前调用
+func SetKlineConfig(cfg config.KlinesConfig) {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultKlineBaseURL
+	}
+	warmup := cfg.Warmup
+	if warmup <= 0 {
+		warmup = defaultWarmup
+	}
+	var store CandleStore
+	if !cfg.DisableCache {
+		dir := cfg.CacheDir
+		if dir == "" {
+			dir = defaultKlineCache
+		}
+		// 缓存至少能容纳一次预热所需的K线
+		limit := cfg.CacheLimit
+		if limit <= 0 {
+			limit = defaultFileCandleLimit
+		}
+		store = newFileCandleStore(dir, max(limit, warmup))
+	}
+
+	klineMu.Lock()
+	defer klineMu.Unlock()
+	klineBaseURL = baseURL
+	klineWarmup = warmup
+	candleStore = store
+}
+
+// SetCandleStore 替换历史K线缓存，传入 nil 关闭缓存
+func SetCandleStore(store CandleStore) {
+	klineMu.Lock()
+	defer klineMu.Unlock()
+	candleStore = store
+}
+
+func klineSettings() (baseURL string, warmup int, store CandleStore) {
+	klineMu.RLock()
+	defer klineMu.RUnlock()
+	return klineBaseURL, klineWarmup, candleStore
+}
+
+// fetchHistoricalKlines 拉取最近 limit 根K线（包含尚未收盘的当前K线），按开盘时间升序。
+// 有缓存时只从缓存的最后一根向后补齐，数量不足时再按 endTime 向前分页；
+// 交易对上市时间较短时返回的K线可能少于 limit
+func fetchHistoricalKlines(symbol, interval string, limit int) ([]Candle, error) {
+	baseURL, _, store := klineSettings()
+	if limit <= 0 {
+		limit = defaultWarmup
+	}
+
+	var cached []Candle
+	if store != nil {
+		var err error
+		if cached, err = store.Load(symbol, interval, limit); err != nil {
+			logx.Errorf("Failed to load cached klines %s %s: %v", strings.ToUpper(symbol), interval, err)
+			cached = nil
+		}
+	}
+
+	// 缓存中间缺失的K线（如实时流断开期间）通过 REST 补齐
+	var fresh []Candle
+	for _, g := range findGaps(cached) {
+		page, err := fetchKlineRange(baseURL, symbol, interval, g.from, g.to)
+		if err != nil {
+			return nil, err
+		}
+		logx.Infof("Backfilled %d klines %s %s in cache gap %s - %s", len(page), strings.ToUpper(symbol), interval,
+			time.UnixMilli(g.from).Format(time.RFC3339), time.UnixMilli(g.to).Format(time.RFC3339))
+		fresh = append(fresh, page...)
+	}
+	cached = mergeCandles(cached, fresh)
+
+	// 最新的K线：有缓存时从缓存最后一根（可能在缓存时尚未收盘）开始向后分页
+	if len(cached) > 0 {
+		start := cached[len(cached)-1].OpenTime
+		for {
+			page, err := fetchKlinePage(baseURL, symbol, interval, start, 0, klinePageLimit)
+			if err != nil {
+				return nil, err
+			}
+			fresh = append(fresh, page...)
+			if len(page) < klinePageLimit {
+				break
+			}
+			start = page[len(page)-1].OpenTime + 1
+		}
+	} else {
+		page, err := fetchKlinePage(baseURL, symbol, interval, 0, 0, minInt(limit, klinePageLimit))
+		if err != nil {
+			return nil, err
+		}
+		fresh = page
+	}
+	candles := mergeCandles(cached, fresh)
+
+	// 数量不足时按 endTime 向前分页，返回数量少于请求数量说明已到上市时的第一根K线
+	for len(candles) > 0 && len(candles) < limit {
+		n := minInt(limit-len(candles), klinePageLimit)
+		page, err := fetchKlinePage(baseURL, symbol, interval, 0, candles[0].OpenTime-1, n)
+		if err != nil {
+			return nil, err
+		}
+		fresh = append(fresh, page...)
+		candles = mergeCandles(page, candles)
+		if len(page) < n {
+			break
+		}
+	}
+
+	saveCandles(symbol, interval, closedCandles(fresh))
+
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// fetchKlinePage 请求一页K线：startTime 不为 0 时返回此后的 limit 根（不超过 endTime），
+// 只有 endTime 时返回此前的 limit 根，都为 0 时返回最新的 limit 根
+func fetchKlinePage(baseURL, symbol, interval string, startTime, endTime int64, limit int) ([]Candle, error) {
+	// Binance Futures REST (USDT-M): https://fapi.binance.com/fapi/v1/klines
+	// Response: [[openTime, open, high, low, close, volume, closeTime, ...], ...]
+	q := url.Values{}
+	q.Set("symbol", strings.ToUpper(symbol))
+	q.Set("interval", interval)
+	q.Set("limit", strconv.Itoa(limit))
+	if startTime > 0 {
+		q.Set("startTime", strconv.FormatInt(startTime, 10))
+	}
+	if endTime > 0 {
+		q.Set("endTime", strconv.FormatInt(endTime, 10))
+	}
+
+	resp, err := klineHTTPClient.Get(baseURL + "/fapi/v1/klines?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("binance klines status=%d body=%s", resp.StatusCode, string(b))
+	}
+	var raw [][]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	result := make([]Candle, 0, len(raw))
+	for _, it := range raw {
+		if len(it) < 7 {
+			continue
+		}
+		// openTime, open, high, low, close, volume, closeTime
+		openTime, _ := it[0].(float64)
+		openStr, _ := it[1].(string)
+		highStr, _ := it[2].(string)
+		lowStr, _ := it[3].(string)
+		closeStr, _ := it[4].(string)
+		volumeStr, _ := it[5].(string)
+		closeTime, _ := it[6].(float64)
+		c := Candle{
+			OpenTime:  int64(openTime),
+			Open:      toFloat(openStr),
+			High:      toFloat(highStr),
+			Low:       toFloat(lowStr),
+			Close:     toFloat(closeStr),
+			Volume:    toFloat(volumeStr),
+			CloseTime: int64(closeTime),
+		}
+		// quoteVolume, trades, takerBuyBase, takerBuyQuote
+		if len(it) >= 11 {
+			quoteStr, _ := it[7].(string)
+			takerBaseStr, _ := it[9].(string)
+			takerQuoteStr, _ := it[10].(string)
+			c.QuoteVolume = toFloat(quoteStr)
+			c.TakerBuyVolume = toFloat(takerBaseStr)
+			c.TakerBuyQuoteVolume = toFloat(takerQuoteStr)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// fetchKlineRange 拉取开盘时间在 [from, to] 内的K线，超过单次上限时向后分页
+func fetchKlineRange(baseURL, symbol, interval string, from, to int64) ([]Candle, error) {
+	var result []Candle
+	for from <= to {
+		page, err := fetchKlinePage(baseURL, symbol, interval, from, to, klinePageLimit)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page...)
+		if len(page) < klinePageLimit {
+			break
+		}
+		from = page[len(page)-1].OpenTime + 1
+	}
+	return result, nil
+}
+
+// klineGap 缺失K线的开盘时间范围 [from, to]
+type klineGap struct {
+	from int64
+	to   int64
+}
+
+// findGaps 检测相邻K线之间的缺口：币安K线的收盘时间为下一根开盘时间减 1ms，
+// 该规则对 1M 等长度不固定的周期同样成立
+func findGaps(candles []Candle) []klineGap {
+	var gaps []klineGap
+	for i := 1; i < len(candles); i++ {
+		prev, c := candles[i-1], candles[i]
+		if c.OpenTime > prev.CloseTime+1 {
+			gaps = append(gaps, klineGap{from: prev.CloseTime + 1, to: c.OpenTime - 1})
+		}
+	}
+	return gaps
+}
+
+// saveCandles 将已收盘的K线写入存储，未配置存储时跳过
+func saveCandles(symbol, interval string, candles []Candle) {
+	_, _, store := klineSettings()
+	if store == nil || len(candles) == 0 {
+		return
+	}
+	if err := store.Save(symbol, interval, candles); err != nil {
+		logx.Errorf("Failed to save klines %s %s: %v", strings.ToUpper(symbol), interval, err)
+	}
+}
+
+// mergeCandles 按开盘时间合并去重，同一根K线以 b 为准，结果按开盘时间升序
+func mergeCandles(a, b []Candle) []Candle {
+	byOpen := make(map[int64]Candle, len(a)+len(b))
+	for _, c := range a {
+		byOpen[c.OpenTime] = c
+	}
+	for _, c := range b {
+		byOpen[c.OpenTime] = c
+	}
+	merged := make([]Candle, 0, len(byOpen))
+	for _, c := range byOpen {
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].OpenTime < merged[j].OpenTime })
+	return merged
+}
+
+// closedCandles 去掉尚未收盘的K线
+func closedCandles(candles []Candle) []Candle {
+	now := time.Now().UnixMilli()
+	closed := make([]Candle, 0, len(candles))
+	for _, c := range candles {
+		if c.CloseTime < now {
+			closed = append(closed, c)
+		}
+	}
+	return closed
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,247 @@
+package rsi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"notice/api/config"
+)
+
+// fakeKlineServer 按 fapi/v1/klines 的 startTime/endTime/limit 语义返回 1m K线
+type fakeKlineServer struct {
+	mu       sync.Mutex
+	count    int // K线数量，开盘时间为 i*60000
+	requests int
+}
+
+func (f *fakeKlineServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	start, end := 0, f.count // [start, end)
+	if e := q.Get("endTime"); e != "" {
+		t, _ := strconv.ParseInt(e, 10, 64)
+		end = minInt(int(t/60000)+1, f.count)
+	}
+	if s := q.Get("startTime"); s != "" {
+		t, _ := strconv.ParseInt(s, 10, 64)
+		start = int((t + 59999) / 60000)
+		end = minInt(start+limit, end)
+	} else {
+		start = end - limit
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	rows := [][]interface{}{}
+	for i := start; i < end; i++ {
+		price := strconv.Itoa(100 + i%7)
+		rows = append(rows, []interface{}{i * 60000, price, price, price, price, "1", i*60000 + 59999})
+	}
+	json.NewEncoder(w).Encode(rows)
+}
+
+func (f *fakeKlineServer) setCount(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count = n
+}
+
+func (f *fakeKlineServer) stats() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.requests
+	f.requests = 0
+	return n
+}
+
+func useFakeKlines(t *testing.T, count int, cacheDir string) *fakeKlineServer {
+	t.Helper()
+	fake := &fakeKlineServer{count: count}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg := config.KlinesConfig{BaseURL: srv.URL, CacheDir: cacheDir, DisableCache: cacheDir == ""}
+	SetKlineConfig(cfg)
+	t.Cleanup(func() { SetKlineConfig(config.KlinesConfig{DisableCache: true}) })
+	return fake
+}
+
+func checkContiguous(t *testing.T, candles []Candle, want int, last int) {
+	t.Helper()
+	if len(candles) != want {
+		t.Fatalf("got %d candles, want %d", len(candles), want)
+	}
+	for i, c := range candles {
+		if c.OpenTime != int64(last-want+1+i)*60000 {
+			t.Fatalf("candle %d open time %d is not contiguous", i, c.OpenTime)
+		}
+	}
+}
+
+func TestFetchHistoricalKlinesPagesBackwards(t *testing.T) {
+	fake := useFakeKlines(t, 4000, "")
+
+	candles, err := fetchHistoricalKlines("btcusdt", "1m", 3500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContiguous(t, candles, 3500, 3999)
+	if n := fake.stats(); n != 3 {
+		t.Fatalf("expected 3 pages (1500+1500+500), got %d requests", n)
+	}
+
+	// 上市时间较短：返回全部历史
+	fake.setCount(300)
+	candles, err = fetchHistoricalKlines("newusdt", "1M", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContiguous(t, candles, 300, 299)
+}
+
+func TestFetchHistoricalKlinesUsesCache(t *testing.T) {
+	fake := useFakeKlines(t, 2000, t.TempDir())
+
+	if _, err := fetchHistoricalKlines("btcusdt", "1m", 1800); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.stats(); n != 2 {
+		t.Fatalf("expected 2 pages on cold start, got %d requests", n)
+	}
+
+	// 重启后只补齐缓存之后的K线
+	fake.setCount(2010)
+	candles, err := fetchHistoricalKlines("btcusdt", "1m", 1800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContiguous(t, candles, 1800, 2009)
+	if n := fake.stats(); n != 1 {
+		t.Fatalf("expected 1 request with warm cache, got %d", n)
+	}
+
+	// 需要更长的历史时从缓存最早的K线继续向前分页
+	candles, err = fetchHistoricalKlines("btcusdt", "1m", 2010)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContiguous(t, candles, 2010, 2009)
+	if n := fake.stats(); n != 2 {
+		t.Fatalf("expected forward and backward requests, got %d", n)
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	candles := closes(1, 2, 3, 4)
+	for i := range candles {
+		candles[i].OpenTime = int64(i) * 60000
+		candles[i].CloseTime = int64(i)*60000 + 59999
+	}
+	if gaps := findGaps(candles); len(gaps) != 0 {
+		t.Fatalf("contiguous candles should have no gaps: %+v", gaps)
+	}
+
+	gapped := []Candle{candles[0], candles[3]}
+	gaps := findGaps(gapped)
+	if len(gaps) != 1 || gaps[0].from != 60000 || gaps[0].to != 3*60000-1 {
+		t.Fatalf("unexpected gaps: %+v", gaps)
+	}
+}
+
+func TestFetchHistoricalKlinesFillsCacheGaps(t *testing.T) {
+	dir := t.TempDir()
+	fake := useFakeKlines(t, 100, dir)
+
+	// 缓存中缺少 40-59
+	full, err := fetchKlinePage(klineBaseURL, "btcusdt", "1m", 0, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileCandleStore(dir)
+	if err := store.Save("btcusdt", "1m", append(append([]Candle{}, full[:40]...), full[60:]...)); err != nil {
+		t.Fatal(err)
+	}
+	fake.stats()
+
+	candles, err := fetchHistoricalKlines("btcusdt", "1m", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContiguous(t, candles, 100, 99)
+	if n := fake.stats(); n != 2 {
+		t.Fatalf("expected gap fill and tail requests, got %d", n)
+	}
+	cached, _ := store.Load("btcusdt", "1m", 0)
+	if len(findGaps(cached)) != 0 || len(cached) != 100 {
+		t.Fatalf("gap should be saved to cache, got %d candles", len(cached))
+	}
+}
+
+func TestFileCandleStoreKeepsRecentCandles(t *testing.T) {
+	store := newFileCandleStore(t.TempDir(), 50)
+	var candles []Candle
+	for i := 0; i < 40; i++ {
+		candles = append(candles, Candle{OpenTime: int64(i) * 60000, CloseTime: int64(i)*60000 + 59999, Close: float64(i)})
+	}
+	if err := store.Save("btcusdt", "1m", candles); err != nil {
+		t.Fatal(err)
+	}
+	// 实时流逐根保存收盘K线，超过上限时丢弃最早的
+	for i := 40; i < 60; i++ {
+		if err := store.Save("btcusdt", "1m", []Candle{{OpenTime: int64(i) * 60000, CloseTime: int64(i)*60000 + 59999, Close: float64(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stored, _ := store.Load("btcusdt", "1m", 0)
+	if len(stored) != 50 || stored[0].Close != 10 || stored[49].Close != 59 {
+		t.Fatalf("expected the most recent 50 candles, got %d (%v - %v)", len(stored), stored[0].Close, stored[len(stored)-1].Close)
+	}
+
+	// 上限不少于预热数量
+	SetKlineConfig(config.KlinesConfig{CacheDir: t.TempDir(), CacheLimit: 100, Warmup: 300})
+	t.Cleanup(func() { SetKlineConfig(config.KlinesConfig{DisableCache: true}) })
+	if _, _, s := klineSettings(); s.(*fileCandleStore).limit != 300 {
+		t.Fatalf("cache limit should cover warmup, got %d", s.(*fileCandleStore).limit)
+	}
+}
+
+func TestHandleCloseBackfillsGap(t *testing.T) {
+	dir := t.TempDir()
+	fake := useFakeKlines(t, 50, dir)
+	// 不走真实通知，避免写入 ./storage
+	prevSend := sendAlert
+	sendAlert = func(message, title, topic string) error { return nil }
+	t.Cleanup(func() { sendAlert = prevSend })
+	all, err := fetchKlinePage(klineBaseURL, "btcusdt", "1m", 0, 0, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &watcher{symbol: "btcusdt", interval: "1m", period: 20, th: thresholds{overbought: 70, oversold: 30, hysteresis: 2}}
+	st := w.newState()
+	w.handleClose(st, all[10])
+	fake.stats()
+
+	// 11-19 缺失，收到 20 时补齐
+	w.handleClose(st, all[20])
+	if n := fake.stats(); n != 1 {
+		t.Fatalf("expected one backfill request, got %d", n)
+	}
+	if st.lastTs != all[20].CloseTime || st.set.pipelines[0].ind.(*rsiCalc).count != 11 {
+		t.Fatalf("backfilled candles should update indicators: lastTs=%d", st.lastTs)
+	}
+
+	stored, _ := NewFileCandleStore(dir).Load("btcusdt", "1m", 0)
+	if len(stored) != 11 || len(findGaps(stored)) != 0 {
+		t.Fatalf("expected 11 contiguous stored candles, got %d", len(stored))
+	}
+}
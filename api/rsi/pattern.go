@@ -0,0 +1,337 @@
+package rsi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// K 线形态名称
+const (
+	patternHammer             = "hammer"
+	patternShootingStar       = "shooting_star"
+	patternBullishEngulfing   = "bullish_engulfing"
+	patternBearishEngulfing   = "bearish_engulfing"
+	patternInsideBar          = "inside_bar"
+	patternThreeWhiteSoldiers = "three_white_soldiers"
+	patternThreeBlackCrows    = "three_black_crows"
+	patternDoji               = "doji"
+	patternDragonflyDoji      = "dragonfly_doji"
+	patternGravestoneDoji     = "gravestone_doji"
+)
+
+// patternTitles 告警标题中使用的形态名称
+var patternTitles = map[string]string{
+	patternHammer:             "锤子线",
+	patternShootingStar:       "射击之星",
+	patternBullishEngulfing:   "看涨吞没",
+	patternBearishEngulfing:   "看跌吞没",
+	patternInsideBar:          "内包线",
+	patternThreeWhiteSoldiers: "红三兵",
+	patternThreeBlackCrows:    "三只乌鸦",
+	patternDoji:               "十字星",
+	patternDragonflyDoji:      "蜻蜓十字星",
+	patternGravestoneDoji:     "墓碑十字星",
+}
+
+// defaultPatternRules 未配置形态规则时沿用原有的小实体检测：4h/1d/1M 上 |O-C|/O <= 0.1%
+var defaultPatternRules = []config.PatternRule{
+	{Name: patternDoji, Intervals: []string{"4h", "1d", "1M"}},
+}
+
+// patternRule 补全默认阈值后的形态规则
+type patternRule struct {
+	name        string
+	intervals   []string // 为空匹配所有周期
+	bodyPct     float64
+	bodyRatio   float64
+	shadowRatio float64
+}
+
+var (
+	patternMu    sync.RWMutex
+	patternRules = mustParsePatternRules(defaultPatternRules)
+)
+
+// SetPatternConfig 设置 K 线形态检测规则，应在启动 RSI 任务前调用
+func SetPatternConfig(cfg config.PatternsConfig) {
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = defaultPatternRules
+	}
+
+	var parsed []patternRule
+	if !cfg.Disabled {
+		for _, r := range rules {
+			rule, err := parsePatternRule(r)
+			if err != nil {
+				logx.Errorf("Skip invalid pattern rule: %v", err)
+				continue
+			}
+			parsed = append(parsed, rule)
+		}
+	}
+
+	patternMu.Lock()
+	defer patternMu.Unlock()
+	patternRules = parsed
+}
+
+func mustParsePatternRules(rules []config.PatternRule) []patternRule {
+	parsed := make([]patternRule, 0, len(rules))
+	for _, r := range rules {
+		rule, err := parsePatternRule(r)
+		if err != nil {
+			panic(err)
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed
+}
+
+func parsePatternRule(r config.PatternRule) (patternRule, error) {
+	name := strings.ToLower(r.Name)
+	if _, ok := patternTitles[name]; !ok {
+		return patternRule{}, fmt.Errorf("unknown pattern %q", r.Name)
+	}
+	rule := patternRule{
+		name:        name,
+		intervals:   r.Intervals,
+		bodyPct:     r.BodyPct,
+		bodyRatio:   r.BodyRatio,
+		shadowRatio: r.ShadowRatio,
+	}
+
+	// 默认阈值
+	switch name {
+	case patternHammer, patternShootingStar:
+		rule.bodyRatio = orDefaultFloat(rule.bodyRatio, 0.35)
+		rule.shadowRatio = orDefaultFloat(rule.shadowRatio, 2)
+	case patternDoji:
+		rule.bodyPct = orDefaultFloat(rule.bodyPct, 0.001)
+	case patternDragonflyDoji, patternGravestoneDoji:
+		rule.bodyRatio = orDefaultFloat(rule.bodyRatio, 0.1)
+		rule.shadowRatio = orDefaultFloat(rule.shadowRatio, 0.1)
+	case patternBullishEngulfing, patternBearishEngulfing, patternThreeWhiteSoldiers, patternThreeBlackCrows:
+		rule.bodyPct = orDefaultFloat(rule.bodyPct, 0.001)
+	}
+	return rule, nil
+}
+
+func orDefaultFloat(v, def float64) float64 {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func (r patternRule) appliesTo(interval string) bool {
+	if len(r.intervals) == 0 {
+		return true
+	}
+	for _, i := range r.intervals {
+		if i == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// patternMatch 检测到的形态
+type patternMatch struct {
+	name   string
+	detail string // 触发时的关键比例，用于告警正文
+	candle Candle
+}
+
+// patternDetector 按周期适用的规则检测 K 线形态，保留最近三根收盘K线
+type patternDetector struct {
+	rules  []patternRule
+	recent []Candle
+}
+
+// newPatternDetector 创建适用于该周期的形态检测器，没有适用规则时返回 nil
+func newPatternDetector(interval string) *patternDetector {
+	patternMu.RLock()
+	defer patternMu.RUnlock()
+
+	var rules []patternRule
+	for _, r := range patternRules {
+		if r.appliesTo(interval) {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &patternDetector{rules: rules}
+}
+
+// update 输入一根收盘K线，返回检测到的形态
+func (d *patternDetector) update(c Candle) []patternMatch {
+	d.recent = append(d.recent, c)
+	if len(d.recent) > 3 {
+		d.recent = d.recent[len(d.recent)-3:]
+	}
+
+	var matches []patternMatch
+	specificDoji := false
+	for _, r := range d.rules {
+		if detail, ok := r.match(d.recent); ok {
+			matches = append(matches, patternMatch{name: r.name, detail: detail, candle: c})
+			if r.name == patternDragonflyDoji || r.name == patternGravestoneDoji {
+				specificDoji = true
+			}
+		}
+	}
+
+	// 蜻蜓/墓碑十字星优先于普通十字星，避免同一根K线重复告警
+	if specificDoji {
+		filtered := matches[:0]
+		for _, m := range matches {
+			if m.name != patternDoji {
+				filtered = append(filtered, m)
+			}
+		}
+		matches = filtered
+	}
+	return matches
+}
+
+// candle 形态计算用的几何量
+func body(c Candle) float64        { return math.Abs(c.Close - c.Open) }
+func candleRange(c Candle) float64 { return c.High - c.Low }
+func upperShadow(c Candle) float64 { return c.High - math.Max(c.Open, c.Close) }
+func lowerShadow(c Candle) float64 { return math.Min(c.Open, c.Close) - c.Low }
+func bullish(c Candle) bool        { return c.Close > c.Open }
+func bearish(c Candle) bool        { return c.Close < c.Open }
+
+// bodyPct 实体占开盘价的比例
+func bodyPct(c Candle) float64 {
+	if c.Open == 0 {
+		return 0
+	}
+	return body(c) / math.Abs(c.Open)
+}
+
+// match 判断最近的K线是否满足形态，recent 最后一根为当前K线
+func (r patternRule) match(recent []Candle) (string, bool) {
+	c := recent[len(recent)-1]
+	rng := candleRange(c)
+
+	switch r.name {
+	case patternDoji:
+		if c.Open > 0 && bodyPct(c) <= r.bodyPct {
+			return fmt.Sprintf("|O-C|/O=%.4f%%", bodyPct(c)*100), true
+		}
+
+	case patternDragonflyDoji, patternGravestoneDoji:
+		if rng <= 0 || body(c)/rng > r.bodyRatio {
+			return "", false
+		}
+		short, long := upperShadow(c), lowerShadow(c)
+		if r.name == patternGravestoneDoji {
+			short, long = long, short
+		}
+		if short/rng <= r.shadowRatio && long/rng >= 1-r.bodyRatio-r.shadowRatio {
+			return fmt.Sprintf("实体/振幅=%.2f", body(c)/rng), true
+		}
+
+	case patternHammer, patternShootingStar:
+		if rng <= 0 || body(c)/rng > r.bodyRatio {
+			return "", false
+		}
+		long, short := lowerShadow(c), upperShadow(c)
+		if r.name == patternShootingStar {
+			long, short = short, long
+		}
+		b := body(c)
+		if long >= r.shadowRatio*b && long > 0 && short <= math.Max(b, rng*0.1) {
+			return fmt.Sprintf("影线/实体=%.1f", long/math.Max(b, rng*0.01)), true
+		}
+
+	case patternBullishEngulfing, patternBearishEngulfing:
+		if len(recent) < 2 {
+			return "", false
+		}
+		prev := recent[len(recent)-2]
+		if bodyPct(c) < r.bodyPct || body(c) <= body(prev) {
+			return "", false
+		}
+		if r.name == patternBullishEngulfing && bearish(prev) && bullish(c) && c.Open <= prev.Close && c.Close >= prev.Open {
+			return fmt.Sprintf("实体=%.2f%%", bodyPct(c)*100), true
+		}
+		if r.name == patternBearishEngulfing && bullish(prev) && bearish(c) && c.Open >= prev.Close && c.Close <= prev.Open {
+			return fmt.Sprintf("实体=%.2f%%", bodyPct(c)*100), true
+		}
+
+	case patternInsideBar:
+		if len(recent) < 2 {
+			return "", false
+		}
+		prev := recent[len(recent)-2]
+		if c.High < prev.High && c.Low > prev.Low {
+			return fmt.Sprintf("前高=%.2f 前低=%.2f", prev.High, prev.Low), true
+		}
+
+	case patternThreeWhiteSoldiers, patternThreeBlackCrows:
+		if len(recent) < 3 {
+			return "", false
+		}
+		up := r.name == patternThreeWhiteSoldiers
+		for i, k := range recent {
+			if (up && !bullish(k)) || (!up && !bearish(k)) || bodyPct(k) < r.bodyPct {
+				return "", false
+			}
+			// 收盘一根比一根高（低），且影线较短
+			shadow := upperShadow(k)
+			if !up {
+				shadow = lowerShadow(k)
+			}
+			if shadow > body(k)*0.5 {
+				return "", false
+			}
+			if i == 0 {
+				continue
+			}
+			prev := recent[i-1]
+			if up && (k.Close <= prev.Close || k.Open < prev.Open || k.Open > prev.Close) {
+				return "", false
+			}
+			if !up && (k.Close >= prev.Close || k.Open > prev.Open || k.Open < prev.Close) {
+				return "", false
+			}
+		}
+		return fmt.Sprintf("%.2f → %.2f", recent[0].Open, c.Close), true
+	}
+	return "", false
+}
+
+// sendPatternAlert 推送 K 线形态告警，来源为 pattern
+func sendPatternAlert(symbol, interval string, m patternMatch) {
+	msg := patternAlertMessage(symbol, interval, m)
+	logx.Infof("Candlestick pattern detected at %s: %s", time.Now().Format("2006-01-02 15:04:05"), strings.ReplaceAll(msg.body, "\n", " "))
+	if err := sendAlert(msg.body, msg.title, msg.topic); err != nil {
+		logx.Errorf("Failed to send pattern alert: %v", err)
+	}
+}
+
+// patternAlertMessage 形态告警，标题包含形态名称
+func patternAlertMessage(symbol, interval string, m patternMatch) alertMessage {
+	sym := strings.ToUpper(symbol)
+	c := m.candle
+	return alertMessage{
+		title: fmt.Sprintf("%s %s %s", patternTitles[m.name], sym, interval),
+		body: fmt.Sprintf("%s %s %s (%s)\nO=%.2f H=%.2f L=%.2f C=%.2f @ %s",
+			sym, interval, patternTitles[m.name], m.detail,
+			c.Open, c.High, c.Low, c.Close, time.UnixMilli(c.CloseTime).Format(time.RFC3339)),
+		topic: topic.Of("pattern", symbol, interval, m.name),
+	}
+}
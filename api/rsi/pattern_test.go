@@ -0,0 +1,93 @@
+package rsi
+
+import (
+	"testing"
+
+	"notice/api/config"
+)
+
+func ohlc(o, h, l, c float64) Candle {
+	return Candle{Open: o, High: h, Low: l, Close: c}
+}
+
+func detectPatterns(t *testing.T, name string, candles ...Candle) []string {
+	t.Helper()
+	rule, err := parsePatternRule(config.PatternRule{Name: name})
+	if err != nil {
+		t.Fatalf("parsePatternRule(%s): %v", name, err)
+	}
+	d := &patternDetector{rules: []patternRule{rule}}
+	var names []string
+	for _, c := range candles {
+		names = nil
+		for _, m := range d.update(c) {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+func TestPatternMatches(t *testing.T) {
+	cases := []struct {
+		name    string
+		candles []Candle
+		want    bool
+	}{
+		{patternHammer, []Candle{ohlc(100, 101, 90, 100.5)}, true},
+		{patternHammer, []Candle{ohlc(100, 110, 99, 109)}, false},
+		{patternShootingStar, []Candle{ohlc(100, 101, 90, 100.5)}, false},
+		{patternShootingStar, []Candle{ohlc(100, 110, 99.5, 99.8)}, true},
+		{patternBullishEngulfing, []Candle{ohlc(100, 101, 97, 98), ohlc(97.5, 102, 97, 101.5)}, true},
+		{patternBullishEngulfing, []Candle{ohlc(98, 101, 97, 100), ohlc(97.5, 102, 97, 101.5)}, false},
+		{patternBearishEngulfing, []Candle{ohlc(98, 101, 97, 100), ohlc(100.5, 101, 96, 97)}, true},
+		{patternInsideBar, []Candle{ohlc(100, 110, 90, 105), ohlc(104, 108, 95, 100)}, true},
+		{patternInsideBar, []Candle{ohlc(100, 110, 90, 105), ohlc(104, 111, 95, 100)}, false},
+		{patternThreeWhiteSoldiers, []Candle{ohlc(100, 103.2, 99.8, 103), ohlc(102, 106.3, 101.8, 106), ohlc(105, 109.2, 104.8, 109)}, true},
+		{patternThreeWhiteSoldiers, []Candle{ohlc(100, 103.2, 99.8, 103), ohlc(102, 106.3, 101.8, 106), ohlc(105, 109.2, 104.8, 105.5)}, false},
+		{patternThreeBlackCrows, []Candle{ohlc(109, 109.2, 105.8, 106), ohlc(107, 107.2, 102.8, 103), ohlc(104, 104.2, 99.8, 100)}, true},
+		{patternDoji, []Candle{ohlc(100, 105, 95, 100.05)}, true},
+		{patternDoji, []Candle{ohlc(100, 105, 95, 100.5)}, false},
+		{patternDragonflyDoji, []Candle{ohlc(100, 100.1, 90, 100)}, true},
+		{patternGravestoneDoji, []Candle{ohlc(100, 110, 99.9, 100)}, true},
+		{patternGravestoneDoji, []Candle{ohlc(100, 100.1, 90, 100)}, false},
+	}
+	for i, tc := range cases {
+		got := detectPatterns(t, tc.name, tc.candles...)
+		if matched := len(got) == 1 && got[0] == tc.name; matched != tc.want {
+			t.Errorf("case %d %s: matched=%v want %v (got %v)", i, tc.name, matched, tc.want, got)
+		}
+	}
+}
+
+func TestPatternDetectorPrefersSpecificDoji(t *testing.T) {
+	d := &patternDetector{rules: mustParsePatternRules([]config.PatternRule{
+		{Name: patternDoji}, {Name: patternDragonflyDoji},
+	})}
+	matches := d.update(ohlc(100, 100.1, 90, 100))
+	if len(matches) != 1 || matches[0].name != patternDragonflyDoji {
+		t.Fatalf("expected only dragonfly doji, got %+v", matches)
+	}
+}
+
+func TestSetPatternConfigIntervals(t *testing.T) {
+	defer SetPatternConfig(config.PatternsConfig{})
+
+	// 默认只在 4h/1d/1M 检测十字星
+	SetPatternConfig(config.PatternsConfig{})
+	if newPatternDetector("2h") != nil || newPatternDetector("1M") == nil {
+		t.Fatal("default doji rule should only apply to 4h/1d/1M")
+	}
+
+	SetPatternConfig(config.PatternsConfig{Rules: []config.PatternRule{
+		{Name: "hammer"},
+		{Name: "morning_star"}, // 未知形态，忽略
+	}})
+	if d := newPatternDetector("2h"); d == nil || len(d.rules) != 1 {
+		t.Fatalf("hammer without intervals should apply to every interval, got %+v", d)
+	}
+
+	SetPatternConfig(config.PatternsConfig{Disabled: true})
+	if newPatternDetector("1d") != nil {
+		t.Fatal("disabled config should not create detectors")
+	}
+}
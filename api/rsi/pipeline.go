@@ -0,0 +1,206 @@
+package rsi
+
+import (
+	"fmt"
+	"strings"
+
+	"notice/api/config"
+)
+
+// 指标类型，同时作为告警消息的主题来源（如 macd:btcusdt:4h）
+const (
+	indicatorRSI        = "rsi"
+	indicatorMACD       = "macd"
+	indicatorEMACross   = "ema_cross"
+	indicatorBollinger  = "bollinger"
+	indicatorDivergence = "divergence"
+)
+
+// indicatorSignal 指标告警信号
+type indicatorSignal struct {
+	indicator string     // 指标类型
+	name      string     // 指标名称，如 MACD(12,26,9)
+	signal    string     // 信号类型
+	value     float64    // 触发时的指标读数
+	th        thresholds // 告警阈值（RSI 消息中展示）
+	candle    Candle
+	div       *divergence // 背离信号的两个摆动点
+}
+
+// pipeline 单个指标及其告警条件，div 不为空时按价格与指标的摆动点检测背离
+type pipeline struct {
+	kind  string
+	ind   Indicator
+	cond  condition
+	div   *divergenceDetector
+	th    thresholds
+	value float64
+	ready bool
+}
+
+// indicatorSet 一个交易对/周期上的所有指标，按配置顺序计算
+type indicatorSet struct {
+	pipelines []*pipeline
+}
+
+// defaultIndicators 未配置指标时只监控 RSI
+var defaultIndicators = []config.IndicatorConfig{{Type: indicatorRSI}}
+
+// newIndicatorSet 按配置创建指标，period/th 为监控项的 RSI 周期和阈值
+func newIndicatorSet(cfgs []config.IndicatorConfig, period int, th thresholds) (*indicatorSet, error) {
+	if len(cfgs) == 0 {
+		cfgs = defaultIndicators
+	}
+
+	set := &indicatorSet{}
+	for _, cfg := range cfgs {
+		p, err := newPipeline(cfg, period, th)
+		if err != nil {
+			return nil, err
+		}
+		set.pipelines = append(set.pipelines, p)
+	}
+	return set, nil
+}
+
+func newPipeline(cfg config.IndicatorConfig, period int, th thresholds) (*pipeline, error) {
+	switch kind := strings.ToLower(cfg.Type); kind {
+	case indicatorRSI:
+		if cfg.Period > 0 {
+			period = cfg.Period
+		}
+		if cfg.Overbought > 0 {
+			th.overbought = cfg.Overbought
+		}
+		if cfg.Oversold > 0 {
+			th.oversold = cfg.Oversold
+		}
+		if cfg.Hysteresis > 0 {
+			th.hysteresis = cfg.Hysteresis
+		}
+		return &pipeline{kind: kind, ind: newRSI(period), cond: newLevelAlert(th), th: th}, nil
+
+	case indicatorMACD:
+		fast, slow, signal := orDefault(cfg.Fast, 12), orDefault(cfg.Slow, 26), orDefault(cfg.Signal, 9)
+		if fast >= slow {
+			return nil, fmt.Errorf("macd fast period %d must be less than slow period %d", fast, slow)
+		}
+		return &pipeline{kind: kind, ind: newMACD(fast, slow, signal), cond: newCrossAlert(signalBullish, signalBearish)}, nil
+
+	case indicatorEMACross:
+		fast, slow := orDefault(cfg.Fast, 20), orDefault(cfg.Slow, 50)
+		if fast >= slow {
+			return nil, fmt.Errorf("ema_cross fast period %d must be less than slow period %d", fast, slow)
+		}
+		return &pipeline{kind: kind, ind: newEMACross(fast, slow), cond: newCrossAlert(signalGoldenCross, signalDeathCross)}, nil
+
+	case indicatorBollinger:
+		stdDev := cfg.StdDev
+		if stdDev <= 0 {
+			stdDev = 2
+		}
+		hysteresis := cfg.Hysteresis
+		if hysteresis <= 0 {
+			hysteresis = 0.2
+		}
+		// %B 大于等于1为突破上轨，小于等于0为跌破下轨
+		band := &levelAlert{
+			th:    thresholds{overbought: 1, oversold: 0, hysteresis: hysteresis},
+			above: signalUpperBreakout,
+			below: signalLowerBreakout,
+		}
+		return &pipeline{kind: kind, ind: newBollinger(orDefault(cfg.Period, 20), stdDev), cond: band}, nil
+
+	case indicatorDivergence:
+		if cfg.Period > 0 {
+			period = cfg.Period
+		}
+		detector := newDivergenceDetector(orDefault(cfg.Strength, 5), orDefault(cfg.Lookback, 60))
+		return &pipeline{kind: kind, ind: newRSI(period), div: detector}, nil
+	}
+	return nil, fmt.Errorf("unknown indicator type %q", cfg.Type)
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// update 输入一根收盘K线，返回触发的告警信号
+func (s *indicatorSet) update(c Candle) []indicatorSignal {
+	var signals []indicatorSignal
+	for _, p := range s.pipelines {
+		v, ok := p.ind.Update(c)
+		if !ok {
+			continue
+		}
+		p.value, p.ready = v, true
+		if p.div != nil {
+			for _, d := range p.div.update(c, v) {
+				d := d
+				signals = append(signals, indicatorSignal{
+					indicator: p.kind,
+					name:      p.ind.Name(),
+					signal:    d.kind,
+					value:     v,
+					candle:    c,
+					div:       &d,
+				})
+			}
+			continue
+		}
+		if sig := p.cond.update(v); sig != "" {
+			signals = append(signals, indicatorSignal{
+				indicator: p.kind,
+				name:      p.ind.Name(),
+				signal:    sig,
+				value:     v,
+				th:        p.th,
+				candle:    c,
+			})
+		}
+	}
+	return signals
+}
+
+// ready 所有指标都已完成预热
+func (s *indicatorSet) ready() bool {
+	for _, p := range s.pipelines {
+		if !p.ready {
+			return false
+		}
+	}
+	return true
+}
+
+// values 已完成预热的指标读数，key 为指标名称
+func (s *indicatorSet) values() map[string]float64 {
+	values := make(map[string]float64, len(s.pipelines))
+	for _, p := range s.pipelines {
+		if p.ready {
+			values[p.ind.Name()] = p.value
+		}
+	}
+	return values
+}
+
+// rsi 第一个 RSI 指标的读数
+func (s *indicatorSet) rsi() (value float64, period int, ok bool) {
+	for _, p := range s.pipelines {
+		if r, isRSI := p.ind.(*rsiCalc); isRSI {
+			return p.value, r.period, p.ready
+		}
+	}
+	return 0, 0, false
+}
+
+// names 所有指标名称
+func (s *indicatorSet) names() []string {
+	names := make([]string, 0, len(s.pipelines))
+	for _, p := range s.pipelines {
+		names = append(names, p.ind.Name())
+	}
+	return names
+}
@@ -0,0 +1,450 @@
+package rsi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/expo"
+	"notice/api/model"
+	"notice/api/notification"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 价格告警类型
+const (
+	priceAlertAbove  = "above"  // 价格上穿目标价
+	priceAlertBelow  = "below"  // 价格下穿目标价
+	priceAlertChange = "change" // 时间窗口内涨跌幅达到阈值
+)
+
+// 价格来源
+const (
+	priceSourceMark = "mark" // 标记价格，<symbol>@markPrice@1s
+	priceSourceLast = "last" // 最新成交价，1m K线流的收盘价
+)
+
+const (
+	defaultMaxPriceAlerts = 20
+	defaultAlertCooldown  = 5 * time.Minute
+	maxPriceAlertWindow   = 24 * time.Hour
+)
+
+// priceAlertState 内存中的告警及触发状态
+type priceAlertState struct {
+	alert model.PriceAlert
+
+	// above/below：首个价格确定初始方向，价格回到目标另一侧后才会再次触发
+	initialized bool
+	armed       bool
+	// change：只比较此后的价格，触发后重置，避免同一段行情重复告警
+	since time.Time
+}
+
+// pricePoint 价格采样
+type pricePoint struct {
+	at    time.Time
+	price float64
+}
+
+// priceFeed 单个交易对的价格订阅和最近的价格采样
+type priceFeed struct {
+	unsubscribe func()
+	alerts      map[uint]*priceAlertState
+	history     []pricePoint // 覆盖该交易对最长的 change 窗口，每秒最多一个采样
+}
+
+// priceAlertHit 一次触发
+type priceAlertHit struct {
+	alert model.PriceAlert // 更新触发次数后的告警
+	price float64
+	ref   float64 // change：窗口内的起始价格（最低/最高价）
+	at    time.Time
+}
+
+// PriceAlertManager 用户价格告警：按交易对订阅价格流，触发后只推送给创建告警的 token
+type PriceAlertManager struct {
+	mu          sync.Mutex
+	store       PriceAlertStore
+	source      string
+	maxPerToken int
+	cooldown    time.Duration
+	feeds       map[string]*priceFeed // key: SYMBOL
+
+	// subscribe 订阅交易对价格，返回取消订阅函数；deliver 推送触发的告警。测试中可替换
+	subscribe func(symbol string, onPrice func(price float64, at time.Time)) func()
+	deliver   func(hit priceAlertHit)
+}
+
+var priceAlerts *PriceAlertManager
+
+// InitPriceAlerts 加载已保存的有效告警并订阅对应交易对的价格，应在程序启动时调用
+func InitPriceAlerts(cfg config.PriceAlertsConfig, store PriceAlertStore) error {
+	if cfg.Disabled {
+		return nil
+	}
+	m := newPriceAlertManager(cfg, store)
+	if err := m.load(); err != nil {
+		return err
+	}
+	priceAlerts = m
+	return nil
+}
+
+// GetPriceAlerts 返回价格告警管理器，未启用时返回 nil
+func GetPriceAlerts() *PriceAlertManager {
+	return priceAlerts
+}
+
+func newPriceAlertManager(cfg config.PriceAlertsConfig, store PriceAlertStore) *PriceAlertManager {
+	m := &PriceAlertManager{
+		store:       store,
+		source:      priceSourceMark,
+		maxPerToken: cfg.MaxPerToken,
+		cooldown:    time.Duration(cfg.Cooldown) * time.Second,
+		feeds:       make(map[string]*priceFeed),
+	}
+	if strings.ToLower(cfg.Source) == priceSourceLast {
+		m.source = priceSourceLast
+	}
+	if m.maxPerToken <= 0 {
+		m.maxPerToken = defaultMaxPriceAlerts
+	}
+	if m.cooldown <= 0 {
+		m.cooldown = defaultAlertCooldown
+	}
+	m.subscribe = m.subscribeStream
+	m.deliver = m.sendPriceAlert
+	return m
+}
+
+// subscribeStream 通过共享的组合流订阅标记价格或 1m K线
+func (m *PriceAlertManager) subscribeStream(symbol string, onPrice func(price float64, at time.Time)) func() {
+	if m.source == priceSourceLast {
+		return getKlineStream().subscribe(symbol, "1m", streamConsumer{
+			onKline: func(ev binanceKline) { onPrice(toFloat(ev.K.Close), time.UnixMilli(ev.EventTime)) },
+		})
+	}
+	return getKlineStream().subscribeMarkPrice(symbol, streamConsumer{
+		onMarkPrice: func(ev binanceMarkPrice) { onPrice(toFloat(ev.MarkPrice), time.UnixMilli(ev.EventTime)) },
+	})
+}
+
+func (m *PriceAlertManager) load() error {
+	alerts, err := m.store.Load()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range alerts {
+		m.track(a)
+	}
+	logx.Infof("Loaded %d price alerts", len(alerts))
+	return nil
+}
+
+// track 开始跟踪告警，必要时订阅交易对价格；调用方需持有 m.mu
+func (m *PriceAlertManager) track(a model.PriceAlert) {
+	feed, ok := m.feeds[a.Symbol]
+	if !ok {
+		feed = &priceFeed{alerts: make(map[uint]*priceAlertState)}
+		m.feeds[a.Symbol] = feed
+		symbol := a.Symbol
+		feed.unsubscribe = m.subscribe(symbol, func(price float64, at time.Time) {
+			m.onPrice(symbol, price, at)
+		})
+	}
+	feed.alerts[a.ID] = &priceAlertState{alert: a, since: time.Now()}
+
+	// 告警只推送给创建它的 token
+	if err := expo.GetExpoClient().SetTopicOwner(priceAlertTopic(a), a.Token); err != nil {
+		logx.Errorf("Failed to set owner of price alert %d: %v", a.ID, err)
+	}
+}
+
+// untrack 停止跟踪告警，交易对没有告警时取消订阅；调用方需持有 m.mu
+func (m *PriceAlertManager) untrack(symbol string, id uint) {
+	feed, ok := m.feeds[symbol]
+	if !ok {
+		return
+	}
+	delete(feed.alerts, id)
+	if len(feed.alerts) == 0 {
+		feed.unsubscribe()
+		delete(m.feeds, symbol)
+	}
+}
+
+// Create 校验并保存新告警，开始监控价格
+func (m *PriceAlertManager) Create(a model.PriceAlert) (*model.PriceAlert, error) {
+	a.Symbol = strings.ToUpper(strings.TrimSpace(a.Symbol))
+	a.Type = strings.ToLower(a.Type)
+	a.Direction = strings.ToLower(a.Direction)
+	if err := validatePriceAlert(a); err != nil {
+		return nil, err
+	}
+	// 只接受已注册的 token，否则告警无法定向推送
+	if _, err := expo.GetExpoClient().GetTopics(a.Token); err != nil {
+		return nil, fmt.Errorf("token is not registered: %w", err)
+	}
+	a.Active = true
+	a.TriggerCount = 0
+	a.LastTriggered = nil
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, feed := range m.feeds {
+		for _, st := range feed.alerts {
+			if st.alert.Token == a.Token {
+				count++
+			}
+		}
+	}
+	if count >= m.maxPerToken {
+		return nil, fmt.Errorf("too many active alerts, limit is %d", m.maxPerToken)
+	}
+
+	if err := m.store.Create(&a); err != nil {
+		return nil, err
+	}
+	m.track(a)
+	return &a, nil
+}
+
+func validatePriceAlert(a model.PriceAlert) error {
+	if a.Token == "" || a.Symbol == "" {
+		return fmt.Errorf("token and symbol are required")
+	}
+	switch a.Type {
+	case priceAlertAbove, priceAlertBelow:
+		if a.Price <= 0 {
+			return fmt.Errorf("price must be positive")
+		}
+	case priceAlertChange:
+		if a.Percent <= 0 {
+			return fmt.Errorf("percent must be positive")
+		}
+		if a.Direction != "" && a.Direction != "up" && a.Direction != "down" {
+			return fmt.Errorf("direction must be up or down")
+		}
+		window := time.Duration(a.Window) * time.Second
+		if window < time.Minute || window > maxPriceAlertWindow {
+			return fmt.Errorf("window must be between 1m and 24h")
+		}
+	default:
+		return fmt.Errorf("unknown alert type %q, expect above/below/change", a.Type)
+	}
+	return nil
+}
+
+// List 返回 token 的全部告警
+func (m *PriceAlertManager) List(token string) ([]model.PriceAlert, error) {
+	return m.store.List(token)
+}
+
+// Delete 删除 token 自己的告警
+func (m *PriceAlertManager) Delete(id uint, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts, err := m.store.List(token)
+	if err != nil {
+		return err
+	}
+	for _, a := range alerts {
+		if a.ID != id {
+			continue
+		}
+		if err := m.store.Delete(id); err != nil {
+			return err
+		}
+		m.untrack(a.Symbol, id)
+		expo.GetExpoClient().RemoveTopicOwner(priceAlertTopic(a))
+		return nil
+	}
+	return fmt.Errorf("price alert not found")
+}
+
+// onPrice 处理价格推送：记录采样并检查该交易对的告警，触发的告警在后台推送
+func (m *PriceAlertManager) onPrice(symbol string, price float64, at time.Time) {
+	if price <= 0 {
+		return
+	}
+	for _, hit := range m.evaluate(symbol, price, at) {
+		go m.deliver(hit)
+	}
+}
+
+func (m *PriceAlertManager) evaluate(symbol string, price float64, at time.Time) []priceAlertHit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed, ok := m.feeds[symbol]
+	if !ok {
+		return nil
+	}
+	feed.record(price, at)
+
+	var hits []priceAlertHit
+	for id, st := range feed.alerts {
+		ref, fired := st.check(price, at, feed.history)
+		if !fired {
+			continue
+		}
+		a := &st.alert
+		if a.Recurring && a.LastTriggered != nil && at.Sub(*a.LastTriggered) < m.cooldown {
+			continue
+		}
+
+		triggered := at
+		a.TriggerCount++
+		a.LastTriggered = &triggered
+		st.since = at
+		if !a.Recurring {
+			a.Active = false
+			m.untrack(symbol, id)
+		}
+		hits = append(hits, priceAlertHit{alert: *a, price: price, ref: ref, at: at})
+	}
+	return hits
+}
+
+// record 记录价格采样，只保留最长 change 窗口内的采样
+func (f *priceFeed) record(price float64, at time.Time) {
+	var window time.Duration
+	for _, st := range f.alerts {
+		if st.alert.Type == priceAlertChange {
+			if w := time.Duration(st.alert.Window) * time.Second; w > window {
+				window = w
+			}
+		}
+	}
+	if window == 0 {
+		f.history = nil
+		return
+	}
+
+	if n := len(f.history); n == 0 || at.Sub(f.history[n-1].at) >= time.Second {
+		f.history = append(f.history, pricePoint{at: at, price: price})
+	} else {
+		f.history[n-1].price = price
+	}
+	cutoff := at.Add(-window)
+	i := 0
+	for i < len(f.history) && f.history[i].at.Before(cutoff) {
+		i++
+	}
+	f.history = f.history[i:]
+}
+
+// check 判断告警是否满足触发条件；change 告警返回窗口内的参考价格
+func (st *priceAlertState) check(price float64, at time.Time, history []pricePoint) (float64, bool) {
+	a := st.alert
+	switch a.Type {
+	case priceAlertAbove, priceAlertBelow:
+		beyond := price >= a.Price
+		if a.Type == priceAlertBelow {
+			beyond = price <= a.Price
+		}
+		if !st.initialized {
+			// 创建时价格已在目标另一侧，需先回到目标之内再穿越
+			st.initialized = true
+			st.armed = !beyond
+			return 0, false
+		}
+		if !beyond {
+			st.armed = true
+			return 0, false
+		}
+		if st.armed {
+			st.armed = false
+			return a.Price, true
+		}
+
+	case priceAlertChange:
+		start := at.Add(-time.Duration(a.Window) * time.Second)
+		if st.since.After(start) {
+			start = st.since
+		}
+		low, high := price, price
+		for _, p := range history {
+			if p.at.Before(start) {
+				continue
+			}
+			low = math.Min(low, p.price)
+			high = math.Max(high, p.price)
+		}
+		if a.Direction != "down" && (price-low)/low*100 >= a.Percent {
+			return low, true
+		}
+		if a.Direction != "up" && (high-price)/high*100 >= a.Percent {
+			return high, true
+		}
+	}
+	return 0, false
+}
+
+// priceAlertTopic 每个告警单独的主题，如 price:btcusdt:12
+func priceAlertTopic(a model.PriceAlert) string {
+	return topic.Of("price", a.Symbol, strconv.FormatUint(uint64(a.ID), 10))
+}
+
+// priceAlertMessage 价格告警的标题和正文
+func priceAlertMessage(hit priceAlertHit) (title, body string) {
+	a := hit.alert
+	ts := hit.at.Format(time.RFC3339)
+	switch a.Type {
+	case priceAlertAbove:
+		title = fmt.Sprintf("价格告警 %s 上穿 %s", a.Symbol, formatPrice(a.Price))
+		body = fmt.Sprintf("%s 价格 %s 上穿 %s\n@ %s", a.Symbol, formatPrice(hit.price), formatPrice(a.Price), ts)
+	case priceAlertBelow:
+		title = fmt.Sprintf("价格告警 %s 下穿 %s", a.Symbol, formatPrice(a.Price))
+		body = fmt.Sprintf("%s 价格 %s 下穿 %s\n@ %s", a.Symbol, formatPrice(hit.price), formatPrice(a.Price), ts)
+	case priceAlertChange:
+		change := (hit.price - hit.ref) / hit.ref * 100
+		move := "上涨"
+		if change < 0 {
+			move = "下跌"
+		}
+		window := (time.Duration(a.Window) * time.Second).String()
+		title = fmt.Sprintf("价格告警 %s %s内%s %.2f%%", a.Symbol, window, move, math.Abs(change))
+		body = fmt.Sprintf("%s %s内从 %s %s %.2f%% 至 %s\n@ %s", a.Symbol, window, formatPrice(hit.ref), move,
+			math.Abs(change), formatPrice(hit.price), ts)
+	}
+	if a.Note != "" {
+		body += "\n备注: " + a.Note
+	}
+	return title, body
+}
+
+// formatPrice 去掉多余的小数位，兼顾低价币
+func formatPrice(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// sendPriceAlert 推送触发的告警并保存触发状态，一次性告警推送后取消定向主题
+func (m *PriceAlertManager) sendPriceAlert(hit priceAlertHit) {
+	a := hit.alert
+	title, body := priceAlertMessage(hit)
+	logx.Infof("Price alert %d triggered: %s", a.ID, strings.ReplaceAll(body, "\n", " "))
+
+	t := priceAlertTopic(a)
+	if err := notification.SendNotificationWithTitle(body, title, t); err != nil {
+		logx.Errorf("Failed to send price alert %d: %v", a.ID, err)
+	}
+	if !a.Active {
+		expo.GetExpoClient().RemoveTopicOwner(t)
+	}
+	if err := m.store.Update(a); err != nil {
+		logx.Errorf("Failed to update price alert %d: %v", a.ID, err)
+	}
+}
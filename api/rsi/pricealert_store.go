@@ -0,0 +1,201 @@
+package rsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"notice/api/model"
+
+	"gorm.io/gorm"
+)
+
+// PriceAlertStore 价格告警持久化接口
+type PriceAlertStore interface {
+	// Load 加载所有有效的告警
+	Load() ([]model.PriceAlert, error)
+	// List 返回 token 的全部告警（包括已触发失效的），按创建时间倒序
+	List(token string) ([]model.PriceAlert, error)
+	// Create 保存新告警并设置 ID
+	Create(alert *model.PriceAlert) error
+	// Update 更新告警的触发状态
+	Update(alert model.PriceAlert) error
+	// Delete 删除告警
+	Delete(id uint) error
+}
+
+// dbPriceAlertStore 基于 Postgres 的价格告警存储
+type dbPriceAlertStore struct {
+	db *gorm.DB
+}
+
+// NewDBPriceAlertStore 创建基于数据库的价格告警存储
+func NewDBPriceAlertStore(db *gorm.DB) PriceAlertStore {
+	return &dbPriceAlertStore{db: db}
+}
+
+func (s *dbPriceAlertStore) Load() ([]model.PriceAlert, error) {
+	var alerts []model.PriceAlert
+	if err := s.db.Where("active = ?", true).Order("id").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load price alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (s *dbPriceAlertStore) List(token string) ([]model.PriceAlert, error) {
+	var alerts []model.PriceAlert
+	if err := s.db.Where("token = ?", token).Order("id DESC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list price alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func (s *dbPriceAlertStore) Create(alert *model.PriceAlert) error {
+	return s.db.Create(alert).Error
+}
+
+func (s *dbPriceAlertStore) Update(alert model.PriceAlert) error {
+	return s.db.Model(&model.PriceAlert{}).Where("id = ?", alert.ID).Updates(map[string]interface{}{
+		"active":         alert.Active,
+		"trigger_count":  alert.TriggerCount,
+		"last_triggered": alert.LastTriggered,
+	}).Error
+}
+
+func (s *dbPriceAlertStore) Delete(id uint) error {
+	return s.db.Delete(&model.PriceAlert{}, id).Error
+}
+
+// filePriceAlertStore 基于本地文件的价格告警存储（未配置数据库时使用）
+type filePriceAlertStore struct {
+	filePath string
+	mutex    sync.Mutex
+}
+
+// NewFilePriceAlertStore 创建基于本地 JSON 文件的价格告警存储
+func NewFilePriceAlertStore(filePath string) PriceAlertStore {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		fmt.Printf("Failed to create price alert storage directory: %v\n", err)
+	}
+	return &filePriceAlertStore{filePath: filePath}
+}
+
+func (s *filePriceAlertStore) Load() ([]model.PriceAlert, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alerts, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	active := make([]model.PriceAlert, 0, len(alerts))
+	for _, a := range alerts {
+		if a.Active {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+func (s *filePriceAlertStore) List(token string) ([]model.PriceAlert, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alerts, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var result []model.PriceAlert
+	for i := len(alerts) - 1; i >= 0; i-- {
+		if alerts[i].Token == token {
+			result = append(result, alerts[i])
+		}
+	}
+	return result, nil
+}
+
+func (s *filePriceAlertStore) Create(alert *model.PriceAlert) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alerts, err := s.read()
+	if err != nil {
+		return err
+	}
+	alert.ID = 1
+	for _, a := range alerts {
+		if a.ID >= alert.ID {
+			alert.ID = a.ID + 1
+		}
+	}
+	now := time.Now()
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	return s.write(append(alerts, *alert))
+}
+
+func (s *filePriceAlertStore) Update(alert model.PriceAlert) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alerts, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range alerts {
+		if alerts[i].ID == alert.ID {
+			alerts[i].Active = alert.Active
+			alerts[i].TriggerCount = alert.TriggerCount
+			alerts[i].LastTriggered = alert.LastTriggered
+			alerts[i].UpdatedAt = time.Now()
+			return s.write(alerts)
+		}
+	}
+	return fmt.Errorf("price alert %d not found", alert.ID)
+}
+
+func (s *filePriceAlertStore) Delete(id uint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alerts, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i := range alerts {
+		if alerts[i].ID == id {
+			alerts = append(alerts[:i], alerts[i+1:]...)
+			return s.write(alerts)
+		}
+	}
+	return nil
+}
+
+// read 从文件读取告警列表
+func (s *filePriceAlertStore) read() ([]model.PriceAlert, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return []model.PriceAlert{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []model.PriceAlert
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// write 写入告警列表到文件
+func (s *filePriceAlertStore) write(alerts []model.PriceAlert) error {
+	data, err := json.MarshalIndent(alerts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filePath, data, 0o644)
+}
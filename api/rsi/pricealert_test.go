@@ -0,0 +1,134 @@
+package rsi
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notice/api/config"
+	"notice/api/expo"
+	"notice/api/model"
+)
+
+const testAlertToken = "ExponentPushToken[price-alert-test]"
+
+// newTestPriceAlerts 使用本地文件存储，价格订阅只记录交易对
+func newTestPriceAlerts(t *testing.T) (*PriceAlertManager, map[string]int) {
+	t.Helper()
+	expo.GetExpoClient().AddToken(testAlertToken)
+
+	subs := make(map[string]int)
+	m := newPriceAlertManager(config.PriceAlertsConfig{Cooldown: 60}, NewFilePriceAlertStore(filepath.Join(t.TempDir(), "price_alerts.json")))
+	m.subscribe = func(symbol string, _ func(float64, time.Time)) func() {
+		subs[symbol]++
+		return func() { subs[symbol]-- }
+	}
+	m.deliver = func(priceAlertHit) {}
+	return m, subs
+}
+
+func TestPriceAlertCrossing(t *testing.T) {
+	m, subs := newTestPriceAlerts(t)
+	a, err := m.Create(model.PriceAlert{Token: testAlertToken, Symbol: "btcusdt", Type: "above", Price: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subs["BTCUSDT"] != 1 {
+		t.Fatalf("expected BTCUSDT price subscription, got %v", subs)
+	}
+
+	now := time.Now()
+	// 创建时已在目标之上：需先回到目标之下
+	if hits := m.evaluate("BTCUSDT", 100500, now); len(hits) != 0 {
+		t.Fatalf("should not fire when already above: %+v", hits)
+	}
+	m.evaluate("BTCUSDT", 99000, now.Add(time.Second))
+	hits := m.evaluate("BTCUSDT", 100001, now.Add(2*time.Second))
+	if len(hits) != 1 || hits[0].alert.ID != a.ID || hits[0].alert.Active {
+		t.Fatalf("expected one-shot alert to fire and deactivate, got %+v", hits)
+	}
+	if subs["BTCUSDT"] != 0 {
+		t.Fatal("one-shot alert should unsubscribe after firing")
+	}
+	if hits := m.evaluate("BTCUSDT", 99000, now.Add(3*time.Second)); len(hits) != 0 {
+		t.Fatal("fired one-shot alert should no longer be evaluated")
+	}
+}
+
+func TestPriceAlertRecurringCooldown(t *testing.T) {
+	m, _ := newTestPriceAlerts(t)
+	if _, err := m.Create(model.PriceAlert{Token: testAlertToken, Symbol: "ethusdt", Type: "below", Price: 3000, Recurring: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	m.evaluate("ETHUSDT", 3100, now)
+	if hits := m.evaluate("ETHUSDT", 2990, now.Add(time.Second)); len(hits) != 1 || !hits[0].alert.Active {
+		t.Fatalf("expected recurring alert to fire and stay active, got %+v", hits)
+	}
+	// 冷却期内再次穿越不告警
+	m.evaluate("ETHUSDT", 3010, now.Add(10*time.Second))
+	if hits := m.evaluate("ETHUSDT", 2995, now.Add(20*time.Second)); len(hits) != 0 {
+		t.Fatalf("should not fire within cooldown: %+v", hits)
+	}
+	m.evaluate("ETHUSDT", 3010, now.Add(70*time.Second))
+	hits := m.evaluate("ETHUSDT", 2995, now.Add(80*time.Second))
+	if len(hits) != 1 || hits[0].alert.TriggerCount != 2 {
+		t.Fatalf("expected second trigger after cooldown, got %+v", hits)
+	}
+}
+
+func TestPriceAlertPercentChange(t *testing.T) {
+	m, _ := newTestPriceAlerts(t)
+	_, err := m.Create(model.PriceAlert{Token: testAlertToken, Symbol: "solusdt", Type: "change", Percent: 5, Direction: "up", Window: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	m.evaluate("SOLUSDT", 100, start)
+	m.evaluate("SOLUSDT", 98, start.Add(10*time.Minute))
+	if hits := m.evaluate("SOLUSDT", 102, start.Add(20*time.Minute)); len(hits) != 0 {
+		t.Fatalf("4%% move should not fire: %+v", hits)
+	}
+	hits := m.evaluate("SOLUSDT", 103, start.Add(30*time.Minute))
+	if len(hits) != 1 || hits[0].ref != 98 {
+		t.Fatalf("expected 5%% rise from the window low 98, got %+v", hits)
+	}
+	title, _ := priceAlertMessage(hits[0])
+	if title != "价格告警 SOLUSDT 1h0m0s内上涨 5.10%" {
+		t.Fatalf("unexpected title %q", title)
+	}
+}
+
+func TestPriceAlertValidationAndDelete(t *testing.T) {
+	m, subs := newTestPriceAlerts(t)
+	invalid := []model.PriceAlert{
+		{Token: testAlertToken, Symbol: "btcusdt", Type: "above"},
+		{Token: testAlertToken, Symbol: "btcusdt", Type: "change", Percent: 5, Window: 10},
+		{Token: testAlertToken, Symbol: "btcusdt", Type: "cross", Price: 1},
+		{Token: "ExponentPushToken[unknown]", Symbol: "btcusdt", Type: "above", Price: 1},
+	}
+	for _, a := range invalid {
+		if _, err := m.Create(a); err == nil {
+			t.Errorf("expected validation error for %+v", a)
+		}
+	}
+
+	a, err := m.Create(model.PriceAlert{Token: testAlertToken, Symbol: "btcusdt", Type: "below", Price: 90000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(a.ID, "ExponentPushToken[other]"); err == nil {
+		t.Fatal("other tokens should not delete the alert")
+	}
+	if err := m.Delete(a.ID, testAlertToken); err != nil {
+		t.Fatal(err)
+	}
+	if subs["BTCUSDT"] != 0 {
+		t.Fatal("deleting the last alert should unsubscribe")
+	}
+	if list, _ := m.List(testAlertToken); len(list) != 0 {
+		t.Fatalf("expected no alerts after delete, got %+v", list)
+	}
+}
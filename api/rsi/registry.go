@@ -0,0 +1,237 @@
+package rsi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+)
+
+// WatcherStatus RSI watcher 运行状态
+type WatcherStatus struct {
+	Symbol         string    `json:"symbol"`
+	Interval       string    `json:"interval"`
+	Period         int       `json:"period"`
+	Overbought     float64   `json:"overbought"`
+	Oversold       float64   `json:"oversold"`
+	Hysteresis     float64   `json:"hysteresis"`
+	Connected      bool      `json:"connected"`
+	WarmedUp       bool      `json:"warmed_up"`
+	LastRSI        float64   `json:"last_rsi"`
+	LastCandleTime time.Time `json:"last_candle_time,omitempty"`
+	Indicators     []string  `json:"indicators"`
+	LastError      string    `json:"last_error,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+
+	Values map[string]float64 `json:"values,omitempty"` // 最新指标读数，key 为指标名称
+}
+
+// watcher 单个交易对/周期的 RSI 监控
+type watcher struct {
+	symbol   string
+	interval string
+	period   int
+	th       thresholds
+	lookback int // 预热拉取的K线数量，为 0 时使用 Klines 配置
+
+	indicators []config.IndicatorConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	status WatcherStatus
+}
+
+func (w *watcher) setConnected(connected bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.Connected = connected
+	if connected {
+		w.status.LastError = ""
+	}
+}
+
+func (w *watcher) setReading(set *indicatorSet, closeTime time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.LastRSI, _, _ = set.rsi()
+	w.status.Values = set.values()
+	w.status.LastCandleTime = closeTime
+	w.status.WarmedUp = set.ready()
+}
+
+func (w *watcher) setError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.LastError = err.Error()
+}
+
+func (w *watcher) snapshot() WatcherStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	status := w.status
+	status.Values = make(map[string]float64, len(w.status.Values))
+	for name, v := range w.status.Values {
+		status.Values[name] = v
+	}
+	return status
+}
+
+// WatcherRegistry 管理运行中的 RSI watcher，支持运行时添加和停止
+type WatcherRegistry struct {
+	mu       sync.Mutex
+	watchers map[string]*watcher // key: SYMBOL@interval
+}
+
+var (
+	watchersOnce sync.Once
+	watchers     *WatcherRegistry
+
+	// runFunc watcher 的运行函数，测试中可替换
+	runFunc = runWatcher
+)
+
+// GetWatchers 返回全局 watcher 注册表
+func GetWatchers() *WatcherRegistry {
+	watchersOnce.Do(func() {
+		watchers = &WatcherRegistry{watchers: make(map[string]*watcher)}
+	})
+	return watchers
+}
+
+func watchKey(symbol, interval string) string {
+	return strings.ToUpper(symbol) + "@" + interval
+}
+
+// add 启动一个 watcher，同一交易对/周期已在运行时返回错误；indicators 为空时只监控 RSI
+func (r *WatcherRegistry) add(symbol, interval string, period int, th thresholds, indicators []config.IndicatorConfig, warmup int) (*watcher, error) {
+	if symbol == "" || interval == "" {
+		return nil, fmt.Errorf("symbol and interval are required")
+	}
+	if period <= 0 {
+		period = defaultPeriod
+	}
+	set, err := newIndicatorSet(indicators, period, th)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := watchKey(symbol, interval)
+	if _, ok := r.watchers[key]; ok {
+		return nil, fmt.Errorf("watcher %s already exists", key)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		symbol:   strings.ToLower(symbol),
+		interval: interval,
+		period:   period,
+		th:       th,
+		lookback: warmup,
+
+		indicators: indicators,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		status: WatcherStatus{
+			Symbol:     strings.ToUpper(symbol),
+			Interval:   interval,
+			Period:     period,
+			Overbought: th.overbought,
+			Oversold:   th.oversold,
+			Hysteresis: th.hysteresis,
+			Indicators: set.names(),
+			StartedAt:  time.Now(),
+		},
+	}
+	r.watchers[key] = w
+
+	go func() {
+		defer close(w.done)
+		runFunc(ctx, w)
+	}()
+	return w, nil
+}
+
+// Add 按监控项启动 watcher，每个周期一个；已在运行的周期返回错误，其余周期照常启动
+func (r *WatcherRegistry) Add(cfg config.WatchConfig) ([]WatcherStatus, error) {
+	if cfg.Symbol == "" || len(cfg.Intervals) == 0 {
+		return nil, fmt.Errorf("symbol and intervals are required")
+	}
+
+	var started []WatcherStatus
+	var errs []string
+	for _, interval := range cfg.Intervals {
+		w, err := r.add(cfg.Symbol, interval, cfg.Period, watchThresholds(cfg, interval), cfg.Indicators, cfg.Warmup)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		started = append(started, w.snapshot())
+	}
+	if len(errs) > 0 {
+		return started, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return started, nil
+}
+
+// Stop 停止并移除 watcher，watcher 在后台关闭连接后退出
+func (r *WatcherRegistry) Stop(symbol, interval string) error {
+	key := watchKey(symbol, interval)
+
+	r.mu.Lock()
+	w, ok := r.watchers[key]
+	if ok {
+		delete(r.watchers, key)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("watcher %s not found", key)
+	}
+	w.cancel()
+
+	latestMu.Lock()
+	delete(latest, key)
+	latestMu.Unlock()
+	confluence.remove(symbol, interval)
+	return nil
+}
+
+// Watching 返回交易对是否还有运行中的 watcher
+func (r *WatcherRegistry) Watching(symbol string) bool {
+	prefix := strings.ToUpper(symbol) + "@"
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.watchers {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// List 返回所有 watcher 的状态，按交易对和周期排序
+func (r *WatcherRegistry) List() []WatcherStatus {
+	r.mu.Lock()
+	list := make([]WatcherStatus, 0, len(r.watchers))
+	for _, w := range r.watchers {
+		list = append(list, w.snapshot())
+	}
+	r.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Symbol != list[j].Symbol {
+			return list[i].Symbol < list[j].Symbol
+		}
+		return list[i].Interval < list[j].Interval
+	})
+	return list
+}
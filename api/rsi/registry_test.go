@@ -0,0 +1,73 @@
+package rsi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"notice/api/config"
+)
+
+func TestWatcherRegistryAddStop(t *testing.T) {
+	orig := runFunc
+	defer func() { runFunc = orig }()
+	runFunc = func(ctx context.Context, w *watcher) {
+		set, _ := newIndicatorSet(nil, w.period, w.th)
+		set.pipelines[0].value, set.pipelines[0].ready = 55.5, true
+		w.setConnected(true)
+		w.setReading(set, time.UnixMilli(1700000000000))
+		<-ctx.Done()
+		w.setConnected(false)
+	}
+
+	r := &WatcherRegistry{watchers: make(map[string]*watcher)}
+	started, err := r.Add(config.WatchConfig{Symbol: "solusdt", Intervals: []string{"4h", "1M"}})
+	if err != nil || len(started) != 2 {
+		t.Fatalf("Add: started=%d err=%v", len(started), err)
+	}
+	if started[0].Period != defaultPeriod || started[0].Overbought != 70 {
+		t.Fatalf("unexpected defaults: %+v", started[0])
+	}
+
+	// 同一交易对/周期不能重复启动，其余周期照常启动
+	started, err = r.Add(config.WatchConfig{Symbol: "SOLUSDT", Intervals: []string{"4h", "1d"}, Period: 21})
+	if err == nil || len(started) != 1 || started[0].Interval != "1d" {
+		t.Fatalf("duplicate Add: started=%+v err=%v", started, err)
+	}
+
+	w := r.watchers[watchKey("solusdt", "4h")]
+	deadline := time.Now().Add(time.Second)
+	for !w.snapshot().WarmedUp && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	list := r.List()
+	if len(list) != 3 || list[0].Symbol != "SOLUSDT" || list[0].Interval != "1M" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if st := w.snapshot(); !st.Connected || st.LastRSI != 55.5 || st.Values["RSI(14)"] != 55.5 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+
+	if err := r.Stop("solusdt", "4h"); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not exit after Stop")
+	}
+	if _, err := r.Add(config.WatchConfig{Symbol: "ethusdt", Intervals: []string{"4h"},
+		Indicators: []config.IndicatorConfig{{Type: "stoch"}}}); err == nil {
+		t.Fatal("expected error for unknown indicator")
+	}
+	if err := r.Stop("solusdt", "4h"); err == nil {
+		t.Fatal("expected error stopping unknown watcher")
+	}
+	if len(r.List()) != 2 {
+		t.Fatalf("expected 2 watchers after stop, got %d", len(r.List()))
+	}
+	if !r.Watching("SOLUSDT") || r.Watching("sol") || r.Watching("ethusdt") {
+		t.Fatal("Watching should report symbols with running watchers only")
+	}
+}
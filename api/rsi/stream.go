@@ -0,0 +1,285 @@
+package rsi
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"notice/api/config"
+	"notice/api/websocket"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// binanceStreamURL 币安 U 本位合约组合流地址，连接后通过 SUBSCRIBE 帧订阅
+const binanceStreamURL = "wss://fstream.binance.com/stream"
+
+// streamConsumer 组合流中某个流的订阅者，按订阅的流类型设置对应的回调
+type streamConsumer struct {
+	onKline     func(ev binanceKline)
+	onMarkPrice func(ev binanceMarkPrice)
+	onState     func(connected bool) // 组合流连接/断开时回调
+}
+
+// binanceMarkPrice 标记价格推送（<symbol>@markPrice@1s），包含资金费率
+type binanceMarkPrice struct {
+	EventType       string `json:"e"`
+	EventTime       int64  `json:"E"`
+	Symbol          string `json:"s"`
+	MarkPrice       string `json:"p"`
+	IndexPrice      string `json:"i"`
+	FundingRate     string `json:"r"`
+	NextFundingTime int64  `json:"T"`
+}
+
+// streamRequest SUBSCRIBE/UNSUBSCRIBE 请求帧
+type streamRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// combinedMessage 组合流推送的消息，订阅请求的响应只有 result/id/error
+type combinedMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+	ID     int64           `json:"id"`
+	Error  *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// klineStream 所有 K 线 watcher 和价格订阅共享的单个组合流连接
+type klineStream struct {
+	url       string
+	connector *websocket.WebSocketConnector
+
+	mu        sync.Mutex
+	started   bool
+	connected bool
+	nextID    int
+	requestID int64
+	consumers map[string]map[int]streamConsumer // key: 流名称，如 btcusdt@kline_4h、btcusdt@markPrice@1s
+}
+
+var (
+	klineStreamOnce sync.Once
+	sharedStream    *klineStream
+)
+
+// getKlineStream 返回全局共享的 K 线组合流
+func getKlineStream() *klineStream {
+	klineStreamOnce.Do(func() {
+		sharedStream = newKlineStream(binanceStreamURL)
+	})
+	return sharedStream
+}
+
+func newKlineStream(url string) *klineStream {
+	s := &klineStream{
+		url:       url,
+		consumers: make(map[string]map[int]streamConsumer),
+	}
+	s.connector = websocket.NewWebSocketConnector(config.WebSocketConfig{
+		Name:             "binance-kline",
+		URL:              url,
+		ReconnectDelay:   5,
+		PingInterval:     30,
+		HandshakeTimeout: 10,
+	})
+	s.connector.SetMessageHandler(func(_ int, data []byte) error {
+		s.dispatch(data)
+		return nil
+	})
+	s.connector.SetOnConnect(s.handleConnect)
+	s.connector.SetOnDisconnect(s.handleDisconnect)
+	return s
+}
+
+// klineStreamName 币安 K 线流名称，交易对小写，周期保持原样（1M 为月线）
+func klineStreamName(symbol, interval string) string {
+	return strings.ToLower(symbol) + "@kline_" + interval
+}
+
+// markPriceStreamName 币安每秒推送的标记价格流名称
+func markPriceStreamName(symbol string) string {
+	return strings.ToLower(symbol) + "@markPrice@1s"
+}
+
+// subscribe 订阅交易对/周期的 K 线，返回取消订阅函数
+func (s *klineStream) subscribe(symbol, interval string, consumer streamConsumer) func() {
+	return s.subscribeStream(klineStreamName(symbol, interval), consumer)
+}
+
+// subscribeMarkPrice 订阅交易对的标记价格，返回取消订阅函数
+func (s *klineStream) subscribeMarkPrice(symbol string, consumer streamConsumer) func() {
+	return s.subscribeStream(markPriceStreamName(symbol), consumer)
+}
+
+// subscribeStream 订阅组合流中的一个流，返回取消订阅函数。
+// 同一流的第一个订阅者会发送 SUBSCRIBE，最后一个订阅者取消时发送 UNSUBSCRIBE
+func (s *klineStream) subscribeStream(name string, consumer streamConsumer) func() {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	first := len(s.consumers[name]) == 0
+	if first {
+		s.consumers[name] = make(map[int]streamConsumer)
+	}
+	s.consumers[name][id] = consumer
+	connected := s.connected
+	start := !s.started
+	s.started = true
+	s.mu.Unlock()
+
+	if start {
+		// 首次订阅时建立连接，连接成功后在 handleConnect 中统一订阅
+		s.connector.Start()
+	} else if first && connected {
+		s.send("SUBSCRIBE", []string{name})
+	}
+	if connected && consumer.onState != nil {
+		consumer.onState(true)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.unsubscribe(name, id) })
+	}
+}
+
+func (s *klineStream) unsubscribe(name string, id int) {
+	s.mu.Lock()
+	delete(s.consumers[name], id)
+	last := len(s.consumers[name]) == 0
+	if last {
+		delete(s.consumers, name)
+	}
+	connected := s.connected
+	s.mu.Unlock()
+
+	if last && connected {
+		s.send("UNSUBSCRIBE", []string{name})
+	}
+}
+
+// send 发送订阅请求帧
+func (s *klineStream) send(method string, params []string) {
+	s.mu.Lock()
+	s.requestID++
+	req := streamRequest{Method: method, Params: params, ID: s.requestID}
+	s.mu.Unlock()
+
+	if err := s.connector.SendJSON(req); err != nil {
+		logx.Errorf("Binance kline stream %s %v failed: %v", method, params, err)
+		return
+	}
+	logx.Infof("Binance kline stream %s %v (id=%d)", method, params, req.ID)
+}
+
+// handleConnect 连接（重连）成功后重新订阅所有流，并通知订阅者
+func (s *klineStream) handleConnect() {
+	s.mu.Lock()
+	s.connected = true
+	names := make([]string, 0, len(s.consumers))
+	for name := range s.consumers {
+		names = append(names, name)
+	}
+	consumers := s.allConsumers()
+	s.mu.Unlock()
+
+	logx.Infof("Binance kline stream connected: %s", s.url)
+	if len(names) > 0 {
+		s.send("SUBSCRIBE", names)
+	}
+	for _, c := range consumers {
+		if c.onState != nil {
+			c.onState(true)
+		}
+	}
+}
+
+func (s *klineStream) handleDisconnect(err error) {
+	s.mu.Lock()
+	s.connected = false
+	consumers := s.allConsumers()
+	s.mu.Unlock()
+
+	logx.Errorf("Binance kline stream disconnected: %v", err)
+	for _, c := range consumers {
+		if c.onState != nil {
+			c.onState(false)
+		}
+	}
+}
+
+// allConsumers 调用方需持有 s.mu
+func (s *klineStream) allConsumers() []streamConsumer {
+	var list []streamConsumer
+	for _, byID := range s.consumers {
+		for _, c := range byID {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// dispatch 解析组合流消息并按事件类型分发给对应流的订阅者
+func (s *klineStream) dispatch(data []byte) {
+	var msg combinedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logx.Errorf("Invalid Binance kline stream message: %v", err)
+		return
+	}
+	if msg.Error != nil {
+		logx.Errorf("Binance kline stream request %d failed: %d %s", msg.ID, msg.Error.Code, msg.Error.Msg)
+		return
+	}
+	if msg.Stream == "" || len(msg.Data) == 0 {
+		// 订阅请求的响应
+		return
+	}
+
+	var head struct {
+		EventType string `json:"e"`
+	}
+	if err := json.Unmarshal(msg.Data, &head); err != nil {
+		return
+	}
+
+	switch head.EventType {
+	case "kline":
+		var ev binanceKline
+		if err := json.Unmarshal(msg.Data, &ev); err != nil {
+			return
+		}
+		for _, c := range s.consumersOf(klineStreamName(ev.K.Symbol, ev.K.Interval)) {
+			if c.onKline != nil {
+				c.onKline(ev)
+			}
+		}
+	case "markPriceUpdate":
+		var ev binanceMarkPrice
+		if err := json.Unmarshal(msg.Data, &ev); err != nil {
+			return
+		}
+		for _, c := range s.consumersOf(markPriceStreamName(ev.Symbol)) {
+			if c.onMarkPrice != nil {
+				c.onMarkPrice(ev)
+			}
+		}
+	}
+}
+
+// consumersOf 返回流的订阅者快照，回调在锁外执行
+func (s *klineStream) consumersOf(name string) []streamConsumer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byID := s.consumers[name]
+	consumers := make([]streamConsumer, 0, len(byID))
+	for _, c := range byID {
+		consumers = append(consumers, c)
+	}
+	return consumers
+}
@@ -0,0 +1,115 @@
+package rsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeCombinedStream 模拟币安组合流：记录收到的订阅帧，并可向客户端推送消息
+type fakeCombinedStream struct {
+	requests chan streamRequest
+	conns    chan *websocket.Conn
+}
+
+func newFakeCombinedStream(t *testing.T) (*fakeCombinedStream, *httptest.Server) {
+	f := &fakeCombinedStream{
+		requests: make(chan streamRequest, 16),
+		conns:    make(chan *websocket.Conn, 1),
+	}
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		f.conns <- conn
+		for {
+			var req streamRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			f.requests <- req
+		}
+	}))
+	return f, srv
+}
+
+func (f *fakeCombinedStream) nextRequest(t *testing.T) streamRequest {
+	t.Helper()
+	select {
+	case req := <-f.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for subscription frame")
+	}
+	return streamRequest{}
+}
+
+func TestKlineStreamSubscribeAndDispatch(t *testing.T) {
+	fake, srv := newFakeCombinedStream(t)
+	defer srv.Close()
+
+	s := newKlineStream("ws" + strings.TrimPrefix(srv.URL, "http"))
+	defer s.connector.Close()
+
+	got := make(chan binanceKline, 4)
+	states := make(chan bool, 4)
+	unsubscribe := s.subscribe("BTCUSDT", "4h", streamConsumer{
+		onKline: func(ev binanceKline) { got <- ev },
+		onState: func(connected bool) { states <- connected },
+	})
+
+	req := fake.nextRequest(t)
+	if req.Method != "SUBSCRIBE" || len(req.Params) != 1 || req.Params[0] != "btcusdt@kline_4h" {
+		t.Fatalf("unexpected subscribe frame: %+v", req)
+	}
+	select {
+	case connected := <-states:
+		if !connected {
+			t.Fatal("expected connected state")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for connected state")
+	}
+
+	// 已连接后新增的流单独发送 SUBSCRIBE
+	unsubscribeMonthly := s.subscribe("ethusdt", "1M", streamConsumer{onKline: func(binanceKline) {}})
+	req = fake.nextRequest(t)
+	if req.Method != "SUBSCRIBE" || req.Params[0] != "ethusdt@kline_1M" {
+		t.Fatalf("unexpected subscribe frame: %+v", req)
+	}
+
+	conn := <-fake.conns
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"stream":"btcusdt@kline_4h","data":{"e":"kline","s":"BTCUSDT","k":{"s":"BTCUSDT","i":"4h","T":1700000000000,"c":"42000.5","x":true}}}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"stream":"solusdt@kline_4h","data":{"e":"kline","s":"SOLUSDT","k":{"s":"SOLUSDT","i":"4h","c":"60","x":true}}}`))
+
+	select {
+	case ev := <-got:
+		if ev.K.Close != "42000.5" || !ev.K.IsClosed || ev.K.CloseTime != 1700000000000 {
+			t.Fatalf("unexpected kline: %+v", ev.K)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for kline")
+	}
+
+	// 最后一个订阅者取消时发送 UNSUBSCRIBE
+	unsubscribe()
+	req = fake.nextRequest(t)
+	if req.Method != "UNSUBSCRIBE" || req.Params[0] != "btcusdt@kline_4h" {
+		t.Fatalf("unexpected unsubscribe frame: %+v", req)
+	}
+	unsubscribeMonthly()
+	fake.nextRequest(t)
+
+	select {
+	case ev := <-got:
+		t.Fatalf("unexpected kline for other stream: %+v", ev.K)
+	default:
+	}
+}
@@ -0,0 +1,91 @@
+package rsi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// exchangeInfoTTL 交易对列表的缓存时间
+const exchangeInfoTTL = time.Hour
+
+// binanceIntervals 币安合约支持的K线周期
+var binanceIntervals = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true, "1M": true,
+}
+
+var (
+	symbolsMu      sync.Mutex
+	symbolsBaseURL string
+	symbolsFetched time.Time
+	tradingSymbols map[string]bool
+)
+
+// ValidateWatch 检查周期是否为币安支持的K线周期、交易对是否在 exchangeInfo 中处于交易状态。
+// exchangeInfo 请求失败时只校验周期，不阻止添加监控
+func ValidateWatch(symbol string, intervals []string) error {
+	for _, interval := range intervals {
+		if !binanceIntervals[interval] {
+			return fmt.Errorf("unsupported interval %q", interval)
+		}
+	}
+
+	symbols, err := exchangeSymbols()
+	if err != nil {
+		logx.Errorf("Failed to load exchange info, skip symbol check: %v", err)
+		return nil
+	}
+	if !symbols[strings.ToUpper(symbol)] {
+		return fmt.Errorf("unknown or non-trading symbol %q", strings.ToUpper(symbol))
+	}
+	return nil
+}
+
+// exchangeSymbols 返回处于交易状态的交易对，按 exchangeInfoTTL 缓存
+func exchangeSymbols() (map[string]bool, error) {
+	baseURL, _, _ := klineSettings()
+
+	symbolsMu.Lock()
+	defer symbolsMu.Unlock()
+	if tradingSymbols != nil && symbolsBaseURL == baseURL && time.Since(symbolsFetched) < exchangeInfoTTL {
+		return tradingSymbols, nil
+	}
+
+	resp, err := klineHTTPClient.Get(baseURL + "/fapi/v1/exchangeInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("binance exchangeInfo status=%d body=%s", resp.StatusCode, string(b))
+	}
+	var info struct {
+		Symbols []struct {
+			Symbol string `json:"symbol"`
+			Status string `json:"status"`
+		} `json:"symbols"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+
+	symbols := make(map[string]bool, len(info.Symbols))
+	for _, s := range info.Symbols {
+		if s.Status == "TRADING" {
+			symbols[s.Symbol] = true
+		}
+	}
+	tradingSymbols = symbols
+	symbolsBaseURL = baseURL
+	symbolsFetched = time.Now()
+	return symbols, nil
+}
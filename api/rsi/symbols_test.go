@@ -0,0 +1,45 @@
+package rsi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"notice/api/config"
+)
+
+func TestValidateWatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/exchangeInfo" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"TRADING"},{"symbol":"OLDUSDT","status":"SETTLING"}]}`))
+	}))
+	defer srv.Close()
+	SetKlineConfig(config.KlinesConfig{BaseURL: srv.URL, DisableCache: true})
+	t.Cleanup(func() { SetKlineConfig(config.KlinesConfig{DisableCache: true}) })
+
+	if err := ValidateWatch("btcusdt", []string{"1m", "4h"}); err != nil {
+		t.Fatalf("ValidateWatch() error = %v", err)
+	}
+	for _, tc := range []struct {
+		symbol    string
+		intervals []string
+	}{
+		{"btcusdt", []string{"1m", "4hr"}},
+		{"btcusdtt", []string{"1m"}},
+		{"oldusdt", []string{"1h"}},
+	} {
+		if err := ValidateWatch(tc.symbol, tc.intervals); err == nil {
+			t.Errorf("ValidateWatch(%s, %v) should fail", tc.symbol, tc.intervals)
+		}
+	}
+
+	// exchangeInfo 不可用时只校验周期
+	srv.Close()
+	SetKlineConfig(config.KlinesConfig{BaseURL: srv.URL + "/down", DisableCache: true})
+	if err := ValidateWatch("anyusdt", []string{"15m"}); err != nil {
+		t.Errorf("ValidateWatch() should skip symbol check when exchangeInfo is unavailable, got %v", err)
+	}
+}
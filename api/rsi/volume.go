@@ -0,0 +1,264 @@
+package rsi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"notice/api/config"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 成交量异动检测方法
+const (
+	volumeMethodMean   = "mean"
+	volumeMethodMedian = "median"
+)
+
+// 主动买入占比超过（低于）该值时视为主动买入（卖出）为主
+const (
+	takerBuyDominant  = 0.55
+	takerSellDominant = 0.45
+)
+
+// volumeSettings 补全默认值后的成交量异动配置
+type volumeSettings struct {
+	intervals []string // 为空匹配所有周期
+	lookback  int
+	method    string
+	zscore    float64
+	minQuote  float64
+}
+
+var (
+	volumeMu  sync.RWMutex
+	volumeCfg = &volumeSettings{lookback: 20, method: volumeMethodMean, zscore: 3}
+)
+
+// SetVolumeConfig 设置成交量异动检测参数，应在启动 RSI 任务前调用
+func SetVolumeConfig(cfg config.VolumeConfig) {
+	var settings *volumeSettings
+	if !cfg.Disabled {
+		settings = &volumeSettings{
+			intervals: cfg.Intervals,
+			lookback:  cfg.Lookback,
+			method:    strings.ToLower(cfg.Method),
+			zscore:    cfg.ZScore,
+			minQuote:  cfg.MinQuoteVolume,
+		}
+		if settings.lookback < 5 {
+			settings.lookback = 20
+		}
+		if settings.zscore <= 0 {
+			settings.zscore = 3
+		}
+		if settings.method != volumeMethodMedian {
+			if settings.method != "" && settings.method != volumeMethodMean {
+				logx.Errorf("Unknown volume method %q, fallback to mean", cfg.Method)
+			}
+			settings.method = volumeMethodMean
+		}
+	}
+
+	volumeMu.Lock()
+	defer volumeMu.Unlock()
+	volumeCfg = settings
+}
+
+// volumeSpike 检测到的成交量异动
+type volumeSpike struct {
+	quote    float64 // 成交额 (USDT)
+	baseline float64 // 窗口均值或中位数
+	zscore   float64
+	takerBuy float64 // 主动买入占比，数据缺失时为 -1
+	method   string
+	candle   Candle
+}
+
+// volumeDetector 保存最近 lookback 根K线的成交额，按 z-score 检测放量
+type volumeDetector struct {
+	settings volumeSettings
+	window   []float64
+}
+
+// newVolumeDetector 创建适用于该周期的成交量检测器，关闭或周期不适用时返回 nil
+func newVolumeDetector(interval string) *volumeDetector {
+	volumeMu.RLock()
+	defer volumeMu.RUnlock()
+
+	if volumeCfg == nil {
+		return nil
+	}
+	if len(volumeCfg.intervals) > 0 {
+		found := false
+		for _, i := range volumeCfg.intervals {
+			if i == interval {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return &volumeDetector{settings: *volumeCfg}
+}
+
+// quoteVolume 成交额，缺失时用成交量 × 收盘价估算
+func quoteVolume(c Candle) float64 {
+	if c.QuoteVolume > 0 {
+		return c.QuoteVolume
+	}
+	return c.Volume * c.Close
+}
+
+// takerBuyRatio 主动买入占比，全部为主动卖出时为 0；缺少成交额和主动买入量时返回 -1
+func takerBuyRatio(c Candle) float64 {
+	if c.QuoteVolume > 0 {
+		return c.TakerBuyQuoteVolume / c.QuoteVolume
+	}
+	// 只有成交量时主动买入量为 0 无法区分全部卖出和字段缺失
+	if c.Volume > 0 && c.TakerBuyVolume > 0 {
+		return c.TakerBuyVolume / c.Volume
+	}
+	return -1
+}
+
+// side 按主动买入占比区分放量方向：taker_buy / taker_sell / balanced，数据缺失时为 spike
+func (s volumeSpike) side() string {
+	switch {
+	case s.takerBuy < 0:
+		return "spike"
+	case s.takerBuy >= takerBuyDominant:
+		return "taker_buy"
+	case s.takerBuy <= takerSellDominant:
+		return "taker_sell"
+	}
+	return "balanced"
+}
+
+// volumeSideTitles 告警正文中的放量方向
+var volumeSideTitles = map[string]string{
+	"taker_buy":  "主动买入为主",
+	"taker_sell": "主动卖出为主",
+	"balanced":   "买卖均衡",
+}
+
+// update 输入一根收盘K线，成交额相对窗口的 z-score 超过阈值时返回异动
+func (d *volumeDetector) update(c Candle) (volumeSpike, bool) {
+	q := quoteVolume(c)
+	defer func() {
+		d.window = append(d.window, q)
+		if len(d.window) > d.settings.lookback {
+			d.window = d.window[len(d.window)-d.settings.lookback:]
+		}
+	}()
+
+	if len(d.window) < d.settings.lookback || q <= 0 || q < d.settings.minQuote {
+		return volumeSpike{}, false
+	}
+
+	var baseline, spread float64
+	if d.settings.method == volumeMethodMedian {
+		baseline = median(d.window)
+		deviations := make([]float64, len(d.window))
+		for i, v := range d.window {
+			deviations[i] = math.Abs(v - baseline)
+		}
+		// MAD 乘以 1.4826 后与正态分布的标准差可比
+		spread = median(deviations) * 1.4826
+	} else {
+		baseline, spread = meanStd(d.window)
+	}
+	if spread <= 0 || q <= baseline {
+		return volumeSpike{}, false
+	}
+
+	z := (q - baseline) / spread
+	if z < d.settings.zscore {
+		return volumeSpike{}, false
+	}
+	return volumeSpike{
+		quote:    q,
+		baseline: baseline,
+		zscore:   z,
+		takerBuy: takerBuyRatio(c),
+		method:   d.settings.method,
+		candle:   c,
+	}, true
+}
+
+func meanStd(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// sendVolumeAlert 推送成交量异动告警，来源为 volume
+func sendVolumeAlert(symbol, interval string, s volumeSpike) {
+	msg := volumeAlertMessage(symbol, interval, s)
+	logx.Infof("Volume spike detected: %s", strings.ReplaceAll(msg.body, "\n", " "))
+	if err := sendAlert(msg.body, msg.title, msg.topic); err != nil {
+		logx.Errorf("Failed to send volume alert: %v", err)
+	}
+}
+
+// volumeAlertMessage 成交量异动告警，正文包含主动买入占比
+func volumeAlertMessage(symbol, interval string, s volumeSpike) alertMessage {
+	sym := strings.ToUpper(symbol)
+	c := s.candle
+
+	baseline := "均值"
+	if s.method == volumeMethodMedian {
+		baseline = "中位数"
+	}
+	taker := "主动买入占比: -"
+	if s.takerBuy >= 0 {
+		taker = fmt.Sprintf("主动买入占比: %.1f%% (%s)", s.takerBuy*100, volumeSideTitles[s.side()])
+	}
+
+	change := 0.0
+	if c.Open > 0 {
+		change = (c.Close - c.Open) / c.Open * 100
+	}
+	return alertMessage{
+		title: fmt.Sprintf("成交量异动 %s %s", sym, interval),
+		body: fmt.Sprintf("%s %s 成交额 %s USDT，为%s的 %.1f 倍 (z=%.1f)\n%s\n涨跌: %+.2f%% close=%s @ %s",
+			sym, interval, formatVolume(s.quote), baseline, s.quote/s.baseline, s.zscore, taker,
+			change, formatPrice(c.Close), time.UnixMilli(c.CloseTime).Format(time.RFC3339)),
+		topic: topic.Of("volume", symbol, interval),
+	}
+}
+
+// formatVolume 成交额以亿/万为单位显示
+func formatVolume(v float64) string {
+	switch {
+	case v >= 1e8:
+		return fmt.Sprintf("%.2f亿", v/1e8)
+	case v >= 1e4:
+		return fmt.Sprintf("%.2f万", v/1e4)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,92 @@
+package rsi
+
+import (
+	"strings"
+	"testing"
+
+	"notice/api/config"
+)
+
+func volumeCandle(quote, takerQuote float64) Candle {
+	return Candle{Open: 100, Close: 101, Volume: quote / 100, QuoteVolume: quote, TakerBuyQuoteVolume: takerQuote, CloseTime: 1700000000000}
+}
+
+func TestVolumeSpikeMeanZScore(t *testing.T) {
+	d := &volumeDetector{settings: volumeSettings{lookback: 10, method: volumeMethodMean, zscore: 3}}
+	for i := 0; i < 10; i++ {
+		if _, ok := d.update(volumeCandle(1000+float64(i%2)*100, 500)); ok {
+			t.Fatal("should not alert while the window is filling")
+		}
+	}
+	if _, ok := d.update(volumeCandle(1150, 500)); ok {
+		t.Fatal("normal volume should not alert")
+	}
+	spike, ok := d.update(volumeCandle(5000, 3500))
+	if !ok || spike.zscore < 3 || spike.side() != "taker_buy" {
+		t.Fatalf("expected taker buy spike, got %+v ok=%v", spike, ok)
+	}
+
+	msg := volumeAlertMessage("btcusdt", "1h", spike)
+	if msg.topic != "volume:btcusdt:1h" || !strings.Contains(msg.body, "主动买入占比: 70.0% (主动买入为主)") {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestVolumeSpikeMedianAndFallbacks(t *testing.T) {
+	d := &volumeDetector{settings: volumeSettings{lookback: 5, method: volumeMethodMedian, zscore: 3, minQuote: 2000}}
+	// 一根前期放量不影响中位数基准
+	for _, q := range []float64{1000, 1100, 9000, 1050, 950} {
+		d.update(volumeCandle(q, 0))
+	}
+	spike, ok := d.update(Candle{Open: 100, Close: 99, Volume: 40, TakerBuyVolume: 10})
+	if !ok || spike.quote != 3960 || spike.side() != "taker_sell" {
+		t.Fatalf("expected spike estimated from volume*close, got %+v ok=%v", spike, ok)
+	}
+
+	// 低于 MinQuoteVolume 不告警
+	d = &volumeDetector{settings: volumeSettings{lookback: 5, method: volumeMethodMedian, zscore: 3, minQuote: 20000}}
+	for _, q := range []float64{1000, 1100, 1200, 1050, 950} {
+		d.update(volumeCandle(q, 0))
+	}
+	if _, ok := d.update(volumeCandle(10000, 0)); ok {
+		t.Fatal("spike below MinQuoteVolume should not alert")
+	}
+}
+
+func TestTakerBuyRatio(t *testing.T) {
+	// 有成交额时主动买入为 0 表示全部主动卖出
+	if r := takerBuyRatio(volumeCandle(5000, 0)); r != 0 {
+		t.Fatalf("all-sell candle ratio %v, want 0", r)
+	}
+	if r := takerBuyRatio(Candle{Volume: 40, TakerBuyVolume: 10}); r != 0.25 {
+		t.Fatalf("base volume ratio %v, want 0.25", r)
+	}
+	for _, c := range []Candle{{Volume: 40}, {}} {
+		if r := takerBuyRatio(c); r != -1 {
+			t.Fatalf("missing taker data ratio %v, want -1", r)
+		}
+	}
+
+	spike := volumeSpike{quote: 5000, baseline: 1000, takerBuy: takerBuyRatio(volumeCandle(5000, 0)), candle: volumeCandle(5000, 0)}
+	if spike.side() != "taker_sell" || !strings.Contains(volumeAlertMessage("btcusdt", "1h", spike).body, "主动买入占比: 0.0%") {
+		t.Fatalf("all-sell spike should report 0%% taker buy: %+v", volumeAlertMessage("btcusdt", "1h", spike))
+	}
+}
+
+func TestSetVolumeConfig(t *testing.T) {
+	defer SetVolumeConfig(config.VolumeConfig{})
+
+	SetVolumeConfig(config.VolumeConfig{Intervals: []string{"1h", "4h"}, Method: "MEDIAN"})
+	if newVolumeDetector("1d") != nil {
+		t.Fatal("detector should only apply to configured intervals")
+	}
+	d := newVolumeDetector("4h")
+	if d == nil || d.settings.method != volumeMethodMedian || d.settings.lookback != 20 || d.settings.zscore != 3 {
+		t.Fatalf("unexpected settings %+v", d)
+	}
+
+	SetVolumeConfig(config.VolumeConfig{Disabled: true})
+	if newVolumeDetector("4h") != nil {
+		t.Fatal("disabled config should not create detectors")
+	}
+}
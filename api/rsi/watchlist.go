@@ -0,0 +1,51 @@
+package rsi
+
+import (
+	"notice/api/config"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// defaultPeriod 未配置时的 RSI 周期
+const defaultPeriod = 14
+
+// DefaultWatchlist 未配置 Watchlist 时使用的默认监控列表
+func DefaultWatchlist() []config.WatchConfig {
+	intervals := []string{"2h", "4h", "1d", "1w", "1M"}
+	return []config.WatchConfig{
+		{Symbol: "btcusdt", Intervals: intervals, Period: defaultPeriod},
+		{Symbol: "ethusdt", Intervals: intervals, Period: defaultPeriod},
+	}
+}
+
+// watchThresholds 监控项的阈值：监控项中设置的字段覆盖 RSIAlert 配置
+func watchThresholds(w config.WatchConfig, interval string) thresholds {
+	th := thresholdsFor(w.Symbol, interval)
+	if w.Overbought > 0 {
+		th.overbought = w.Overbought
+	}
+	if w.Oversold > 0 {
+		th.oversold = w.Oversold
+	}
+	if w.Hysteresis > 0 {
+		th.hysteresis = w.Hysteresis
+	}
+	return th
+}
+
+// StartWatchlist 为监控列表中的每个交易对/周期启动一个 RSI watcher，列表为空时使用默认列表
+func StartWatchlist(list []config.WatchConfig) {
+	if len(list) == 0 {
+		list = DefaultWatchlist()
+	}
+
+	for _, w := range list {
+		started, err := GetWatchers().Add(w)
+		for _, st := range started {
+			logx.Infof("Started RSI watcher %s %s RSI(%d)", st.Symbol, st.Interval, st.Period)
+		}
+		if err != nil {
+			logx.Errorf("Failed to start RSI watch entry %s: %v", w.Symbol, err)
+		}
+	}
+}
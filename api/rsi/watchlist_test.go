@@ -0,0 +1,18 @@
+package rsi
+
+import (
+	"testing"
+
+	"notice/api/config"
+)
+
+func TestWatchThresholdsOverrideAlertConfig(t *testing.T) {
+	defer SetAlertConfig(config.RSIAlertConfig{})
+	SetAlertConfig(config.RSIAlertConfig{Overbought: 75, Hysteresis: 3})
+
+	w := config.WatchConfig{Symbol: "solusdt", Intervals: []string{"4h"}, Oversold: 20}
+	th := watchThresholds(w, "4h")
+	if th.overbought != 75 || th.oversold != 20 || th.hysteresis != 3 {
+		t.Fatalf("unexpected thresholds: %+v", th)
+	}
+}
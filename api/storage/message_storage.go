@@ -14,10 +14,19 @@ import (
 
 // MessageRecord 消息记录结构
 type MessageRecord struct {
-	ID        string    `json:"id"`
-	Message   string    `json:"message"`
-	Source    string    `json:"source"`    // webhook, manual, rsi, liquidation, news等
-	Timestamp time.Time `json:"timestamp"`
+	ID         string            `json:"id"`
+	Message    string            `json:"message"`
+	Source     string            `json:"source"`          // webhook, manual, rsi, liquidation, news等
+	Topic      string            `json:"topic,omitempty"` // 消息主题，如 rsi:btcusdt:4h
+	Timestamp  time.Time         `json:"timestamp"`
+	Deliveries []ChannelDelivery `json:"deliveries,omitempty"` // 各通知渠道的发送结果
+}
+
+// ChannelDelivery 单个通知渠道的发送结果
+type ChannelDelivery struct {
+	Channel string `json:"channel"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
 }
 
 // MessageStorage 消息存储管理器
@@ -49,18 +58,17 @@ func GetMessageStorage() *MessageStorage {
 
 // SaveMessage 保存消息到文件
 func (ms *MessageStorage) SaveMessage(message, source string) error {
+	return ms.SaveRecord(MessageRecord{Message: message, Source: source})
+}
+
+// SaveRecord 保存消息记录（含主题、各渠道发送结果）到文件，ID 和时间自动生成
+func (ms *MessageStorage) SaveRecord(record MessageRecord) error {
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
 	// 生成唯一ID
-	id := fmt.Sprintf("%d", time.Now().UnixNano())
-
-	record := MessageRecord{
-		ID:        id,
-		Message:   message,
-		Source:    source,
-		Timestamp: time.Now(),
-	}
+	record.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+	record.Timestamp = time.Now()
 
 	// 读取现有记录
 	messages, err := ms.readMessages()
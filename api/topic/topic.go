@@ -0,0 +1,130 @@
+package topic
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 主题格式为冒号分隔的多段字符串，第一段为消息来源，例如：
+//
+//	rsi:btcusdt:4h                RSI 指标（交易对 + 周期）
+//	pattern:ethusdt:1d:hammer     K 线形态（交易对 + 周期 + 形态）
+//	liquidation                   清算
+//	news                          新闻
+//
+// 订阅按段前缀匹配：订阅 rsi 可收到所有 rsi:* 消息，订阅 rsi:btcusdt 可收到 BTC 所有周期，
+// 某一段写 * 表示匹配任意值，例如 rsi:*:4h。
+
+const (
+	separator = ":"
+	wildcard  = "*"
+)
+
+// legacySources 已更名的消息来源及其旧名称，旧名称的订阅和渠道配置仍然匹配新主题：
+// K 线形态告警的来源由 doji 改为 pattern
+var legacySources = map[string]string{
+	"pattern": "doji",
+}
+
+// monthInterval 月线周期（如 1M）需保留大写，以区别于分钟周期 1m
+var monthInterval = regexp.MustCompile(`^[0-9]+M$`)
+
+// Of 由来源和附加字段（交易对、周期等）构造主题
+func Of(source string, parts ...string) string {
+	segments := make([]string, 0, len(parts)+1)
+	segments = append(segments, source)
+	segments = append(segments, parts...)
+	return Normalize(strings.Join(segments, separator))
+}
+
+// Normalize 规范化主题：小写（月线周期除外）、去掉空白和空段
+func Normalize(t string) string {
+	var segments []string
+	for _, s := range strings.Split(t, separator) {
+		s = strings.TrimSpace(s)
+		if !monthInterval.MatchString(s) {
+			s = strings.ToLower(s)
+		}
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return strings.Join(segments, separator)
+}
+
+// Source 返回主题的消息来源（第一段）
+func Source(t string) string {
+	if i := strings.Index(t, separator); i >= 0 {
+		return t[:i]
+	}
+	return t
+}
+
+// Aliases 返回主题在旧来源名称下的等价主题，如 pattern:btcusdt:4h:hammer → [doji:btcusdt:4h:hammer]
+func Aliases(t string) []string {
+	legacy, ok := legacySources[Source(t)]
+	if !ok {
+		return nil
+	}
+	return []string{legacy + strings.TrimPrefix(t, Source(t))}
+}
+
+// Prefixes 返回主题从最具体到最宽泛的所有前缀，如 rsi:btcusdt:4h → [rsi:btcusdt:4h rsi:btcusdt rsi]
+func Prefixes(t string) []string {
+	segments := strings.Split(t, separator)
+	prefixes := make([]string, 0, len(segments))
+	for i := len(segments); i > 0; i-- {
+		prefixes = append(prefixes, strings.Join(segments[:i], separator))
+	}
+	return prefixes
+}
+
+// Parse 解析订阅列表，支持逗号分隔，返回去重后的规范化主题
+func Parse(values ...string) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			t = Normalize(t)
+			if t == "" || seen[t] {
+				continue
+			}
+			seen[t] = true
+			topics = append(topics, t)
+		}
+	}
+	return topics
+}
+
+// Matches 判断单个订阅是否匹配主题
+func Matches(subscription, t string) bool {
+	subSegments := strings.Split(subscription, separator)
+	topicSegments := strings.Split(t, separator)
+	if len(subSegments) > len(topicSegments) {
+		return false
+	}
+	for i, s := range subSegments {
+		if s != wildcard && s != topicSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchAny 判断订阅列表是否匹配主题；未设置任何订阅表示接收全部消息
+func MatchAny(subscriptions []string, t string) bool {
+	if len(subscriptions) == 0 {
+		return true
+	}
+	t = Normalize(t)
+	topics := append([]string{t}, Aliases(t)...)
+	for _, s := range subscriptions {
+		s = Normalize(s)
+		for _, candidate := range topics {
+			if Matches(s, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package topic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscriptions []string
+		topic         string
+		want          bool
+	}{
+		{"未订阅接收全部", nil, "rsi:btcusdt:4h", true},
+		{"来源前缀", []string{"rsi"}, "rsi:btcusdt:4h", true},
+		{"交易对前缀", []string{"rsi:btcusdt"}, "rsi:btcusdt:1d", true},
+		{"完全匹配", []string{"rsi:btcusdt:4h"}, "rsi:btcusdt:4h", true},
+		{"周期不同", []string{"rsi:btcusdt:4h"}, "rsi:btcusdt:1d", false},
+		{"通配符", []string{"rsi:*:4h"}, "rsi:ethusdt:4h", true},
+		{"订阅比主题更具体", []string{"rsi:btcusdt:4h"}, "rsi", false},
+		{"来源不同", []string{"liquidation", "news"}, "doji:btcusdt:4h", false},
+		{"大小写不敏感", []string{"rsi:btcusdt:4h"}, "RSI:BTCUSDT:4h", true},
+		{"月线与分钟线区分", []string{"rsi:btcusdt:1M"}, "rsi:btcusdt:1m", false},
+		{"月线", []string{"RSI:BTCUSDT:1M"}, "rsi:btcusdt:1M", true},
+		{"旧来源 doji 匹配形态告警", []string{"doji"}, "pattern:btcusdt:4h:hammer", true},
+		{"旧来源 doji 按交易对匹配", []string{"doji:ethusdt"}, "pattern:btcusdt:4h:doji", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchAny(tt.subscriptions, tt.topic); got != tt.want {
+				t.Errorf("MatchAny(%v, %q) = %v, want %v", tt.subscriptions, tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse("rsi:BTCUSDT:4h, liquidation", "news", " liquidation ", "")
+	want := []string{"rsi:btcusdt:4h", "liquidation", "news"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
+
+func TestOfAndPrefixes(t *testing.T) {
+	tp := Of("rsi", "BTCUSDT", "4h")
+	if tp != "rsi:btcusdt:4h" {
+		t.Fatalf("Of() = %q", tp)
+	}
+	if Source(tp) != "rsi" {
+		t.Errorf("Source() = %q", Source(tp))
+	}
+	want := []string{"rsi:btcusdt:4h", "rsi:btcusdt", "rsi"}
+	if got := Prefixes(tp); !reflect.DeepEqual(got, want) {
+		t.Errorf("Prefixes() = %v, want %v", got, want)
+	}
+}
@@ -20,6 +20,7 @@ type WebSocketConnector struct {
 	config         config.WebSocketConfig
 	conn           *websocket.Conn
 	mu             sync.RWMutex
+	writeMu        sync.Mutex // gorilla 连接不支持并发写，心跳与业务消息共用此锁
 	isConnected    bool
 	reconnectCount int
 	ctx            context.Context
@@ -224,7 +225,10 @@ func (w *WebSocketConnector) pingLoop() {
 				return
 			}
 
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			w.writeMu.Lock()
+			err := conn.WriteMessage(websocket.PingMessage, nil)
+			w.writeMu.Unlock()
+			if err != nil {
 				logx.Errorf("WebSocket ping failed: %v", err)
 				
 				w.mu.Lock()
@@ -252,6 +256,8 @@ func (w *WebSocketConnector) SendMessage(messageType int, data []byte) error {
 		return fmt.Errorf("websocket not connected")
 	}
 
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
 	return conn.WriteMessage(messageType, data)
 }
 
@@ -271,6 +277,8 @@ func (w *WebSocketConnector) SendJSON(v interface{}) error {
 		return fmt.Errorf("websocket not connected")
 	}
 
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
 	return conn.WriteJSON(v)
 }
 
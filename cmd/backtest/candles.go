@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"notice/api/rsi"
+)
+
+// loadCandles 读取K线文件：
+//   - .csv：Binance K线格式 openTime,open,high,low,close,volume,closeTime,...，可带表头
+//   - .json：Candle 对象数组，或 REST 接口返回的二维数组
+func loadCandles(path string) ([]rsi.Candle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSV(f)
+	case ".json":
+		return parseJSON(f)
+	}
+	return nil, fmt.Errorf("unsupported file type %q, expect .csv or .json", filepath.Ext(path))
+}
+
+func parseCSV(r io.Reader) ([]rsi.Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var candles []rsi.Candle
+	for line := 1; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < 7 {
+			return nil, fmt.Errorf("line %d: expect at least 7 columns, got %d", line, len(rec))
+		}
+		// 表头
+		if _, err := strconv.ParseInt(strings.TrimSpace(rec[0]), 10, 64); err != nil && line == 1 {
+			continue
+		}
+		c, err := candleFromFields(rec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+func parseJSON(r io.Reader) ([]rsi.Candle, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	candles := make([]rsi.Candle, 0, len(raw))
+	for i, item := range raw {
+		// REST 格式：[openTime, "open", "high", "low", "close", "volume", closeTime, ...]
+		var arr []interface{}
+		if json.Unmarshal(item, &arr) == nil {
+			if len(arr) < 7 {
+				return nil, fmt.Errorf("item %d: expect at least 7 fields, got %d", i, len(arr))
+			}
+			fields := make([]string, len(arr))
+			for j := range fields {
+				fields[j] = fmt.Sprint(arr[j])
+			}
+			c, err := candleFromFields(fields)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %v", i, err)
+			}
+			candles = append(candles, c)
+			continue
+		}
+
+		var c rsi.Candle
+		if err := json.Unmarshal(item, &c); err != nil {
+			return nil, fmt.Errorf("item %d: %v", i, err)
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+// candleFromFields 解析 openTime,open,high,low,close,volume,closeTime，
+// 存在第 8-11 列时同时解析 quoteVolume,trades,takerBuyBase,takerBuyQuote
+func candleFromFields(f []string) (rsi.Candle, error) {
+	n := 7
+	if len(f) >= 11 {
+		n = 11
+	}
+	v := make([]float64, n)
+	for i := range v {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f[i]), 64)
+		if err != nil {
+			return rsi.Candle{}, fmt.Errorf("invalid number %q", f[i])
+		}
+		v[i] = x
+	}
+	c := rsi.Candle{
+		OpenTime:  int64(v[0]),
+		Open:      v[1],
+		High:      v[2],
+		Low:       v[3],
+		Close:     v[4],
+		Volume:    v[5],
+		CloseTime: int64(v[6]),
+	}
+	if n == 11 {
+		c.QuoteVolume = v[7]
+		c.TakerBuyVolume = v[9]
+		c.TakerBuyQuoteVolume = v[10]
+	}
+	return c, nil
+}
+
+// closedOnly 去掉尚未收盘的当前K线
+func closedOnly(candles []rsi.Candle) []rsi.Candle {
+	now := time.Now().UnixMilli()
+	for len(candles) > 0 && candles[len(candles)-1].CloseTime >= now {
+		candles = candles[:len(candles)-1]
+	}
+	return candles
+}
@@ -0,0 +1,197 @@
+// backtest 用历史K线回放 RSI 监控的指标和告警逻辑，输出会推送的告警以及信号之后的收益，
+// 用于在上线前调整阈值和指标参数。不会发送任何通知。
+//
+//	go run ./cmd/backtest -symbol btcusdt -interval 4h -indicators rsi,macd,divergence
+//	go run ./cmd/backtest -f etc/api.yaml -symbol solusdt -interval 4h -file solusdt-4h.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"notice/api/config"
+	"notice/api/database"
+	"notice/api/model"
+	"notice/api/rsi"
+	"notice/api/topic"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	configFile := flag.String("f", "", "config file, uses its RSIAlert/Confluence/Patterns/Volume/Watchlist/Klines/Database settings")
+	symbol := flag.String("symbol", "btcusdt", "trading pair")
+	interval := flag.String("interval", "4h", "kline interval")
+	period := flag.Int("period", 0, "RSI period (default 14 or the watchlist entry)")
+	indicators := flag.String("indicators", "", "comma separated indicators: rsi,macd,ema_cross,bollinger,divergence")
+	overbought := flag.Float64("overbought", 0, "RSI overbought threshold")
+	oversold := flag.Float64("oversold", 0, "RSI oversold threshold")
+	file := flag.String("file", "", "kline file (.csv in Binance kline format or .json), fetches from Binance when empty")
+	limit := flag.Int("limit", 1500, "number of klines to fetch from Binance, pages backwards beyond 1500")
+	horizons := flag.String("horizons", "1,5,10,20", "forward return horizons in bars")
+	quiet := flag.Bool("q", false, "only print the summary")
+	flag.Parse()
+
+	logx.Disable()
+
+	var c config.Config
+	if *configFile != "" {
+		if err := conf.Load(*configFile, &c); err != nil {
+			fatalf("load config: %v", err)
+		}
+		rsi.SetAlertConfig(c.RSIAlert)
+		rsi.SetConfluenceRules(c.Confluence)
+		rsi.SetPatternConfig(c.Patterns)
+		rsi.SetVolumeConfig(c.Volume)
+	}
+	// 与服务共用K线存储，重复回测不必重新下载；配置了数据库时读取 candles 表
+	rsi.SetKlineConfig(c.Klines)
+	if c.Database.Host != "" {
+		if err := database.InitDB(c.Database); err != nil {
+			fmt.Fprintf(os.Stderr, "database unavailable, using file cache: %v\n", err)
+		} else {
+			database.GetDB().Logger = logger.Default.LogMode(logger.Silent)
+			if err := database.AutoMigrate(&model.Candle{}); err != nil {
+				fatalf("migrate candles: %v", err)
+			}
+			rsi.SetCandleStore(rsi.NewDBCandleStore(database.GetDB()))
+		}
+	}
+
+	iv := topic.Normalize(*interval)
+	w := watchEntry(c.Watchlist, *symbol, iv)
+	if *period > 0 {
+		w.Period = *period
+	}
+	if *overbought > 0 {
+		w.Overbought = *overbought
+	}
+	if *oversold > 0 {
+		w.Oversold = *oversold
+	}
+	if *indicators != "" {
+		w.Indicators = nil
+		for _, t := range strings.Split(*indicators, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				w.Indicators = append(w.Indicators, config.IndicatorConfig{Type: t})
+			}
+		}
+	}
+
+	hs, err := parseHorizons(*horizons)
+	if err != nil {
+		fatalf("invalid horizons: %v", err)
+	}
+
+	var candles []rsi.Candle
+	if *file != "" {
+		candles, err = loadCandles(*file)
+	} else {
+		candles, err = rsi.FetchKlines(w.Symbol, iv, *limit)
+		candles = closedOnly(candles)
+	}
+	if err != nil {
+		fatalf("load klines: %v", err)
+	}
+
+	report, err := rsi.Backtest(w, iv, candles, hs)
+	if err != nil {
+		fatalf("backtest: %v", err)
+	}
+	printReport(report, *quiet)
+}
+
+// watchEntry 使用配置中该交易对的监控项（包含周期时），否则只计算 RSI
+func watchEntry(list []config.WatchConfig, symbol, interval string) config.WatchConfig {
+	for _, w := range list {
+		if !strings.EqualFold(w.Symbol, symbol) {
+			continue
+		}
+		for _, i := range w.Intervals {
+			if i == interval {
+				return w
+			}
+		}
+	}
+	return config.WatchConfig{Symbol: strings.ToLower(symbol)}
+}
+
+func parseHorizons(s string) ([]int, error) {
+	var hs []int
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		h, err := strconv.Atoi(p)
+		if err != nil || h <= 0 {
+			return nil, fmt.Errorf("%q is not a positive integer", p)
+		}
+		hs = append(hs, h)
+	}
+	return hs, nil
+}
+
+func printReport(r *rsi.BacktestReport, quiet bool) {
+	fmt.Printf("%s %s: %d candles %s → %s, %d alerts\n\n",
+		r.Symbol, r.Interval, r.Candles, r.From.Format("2006-01-02 15:04"), r.To.Format("2006-01-02 15:04"), len(r.Alerts))
+
+	if r.Suppressed > 0 {
+		fmt.Printf("%d RSI alerts covered by confluence rules are not pushed separately and are excluded\n\n", r.Suppressed)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if !quiet && len(r.Alerts) > 0 {
+		header := "TIME\tSOURCE\tSIGNAL\tCLOSE\tTITLE"
+		for _, h := range r.Horizons {
+			header += fmt.Sprintf("\t+%d", h)
+		}
+		fmt.Fprintln(tw, header)
+		for _, a := range r.Alerts {
+			row := fmt.Sprintf("%s\t%s\t%s\t%.4f\t%s", a.Time.Format("2006-01-02 15:04"), a.Source, a.Signal, a.Close, a.Title)
+			for _, h := range r.Horizons {
+				row += "\t" + formatReturn(a.Returns, h)
+			}
+			fmt.Fprintln(tw, row)
+		}
+		tw.Flush()
+		fmt.Println()
+	}
+
+	header := "SOURCE\tSIGNAL\tCOUNT"
+	for _, h := range r.Horizons {
+		header += fmt.Sprintf("\tAVG +%d\tWIN +%d", h, h)
+	}
+	fmt.Fprintln(tw, header)
+	for _, s := range r.Summary {
+		row := fmt.Sprintf("%s\t%s\t%d", s.Source, s.Signal, s.Count)
+		for _, h := range r.Horizons {
+			if s.Samples[h] == 0 {
+				row += "\t-\t-"
+				continue
+			}
+			row += fmt.Sprintf("\t%+.2f%%\t%.0f%%", s.AvgRet[h], s.WinRate[h])
+		}
+		fmt.Fprintln(tw, row)
+	}
+	tw.Flush()
+}
+
+func formatReturn(returns map[int]float64, h int) string {
+	r, ok := returns[h]
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", r)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}